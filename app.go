@@ -2,15 +2,58 @@ package config
 
 import (
 	"fmt"
-	"github.com/spf13/viper"
+	"github.com/spf13/pflag"
 )
 
 // AppConfig 应用主配置
 type AppConfig struct {
-	AppName string     `mapstructure:"name"`
-	Env     string     `mapstructure:"env"`
-	Etcd    EtcdConfig `mapstructure:"etcd"`
-	Logger  LogConfig  `mapstructure:"logger"`
+	AppName string        `mapstructure:"name"`
+	Env     string        `mapstructure:"env"`
+	Etcd    EtcdConfig    `mapstructure:"etcd"`
+	Logger  LogConfig     `mapstructure:"logger"`
+	Backend BackendConfig `mapstructure:"backend"`
+	Layers  LayerConfig   `mapstructure:"layers"`
+	// EnvOverlay 是否允许环境变量覆盖配置组中的键，见 EnvPrefix
+	EnvOverlay bool `mapstructure:"env_overlay"`
+	// Interpolation 是否解析配置组中的 ${app}、${env}、${key} 等变量，见 refPattern
+	// 开启前需将配置中作为字面量的 ${...} 改写为 $${...}
+	Interpolation bool `mapstructure:"interpolation"`
+	// SecretPatterns 额外的敏感键名模式，匹配的键在本配置管理器的日志中被隐藏
+	// 不影响其他管理器与结构体的格式化输出，全局模式见 AddSecretPatterns
+	SecretPatterns []string `mapstructure:"secret_patterns"`
+	// Encryption 配置组中 ENC[...] 加密值的密钥来源
+	Encryption EncryptionConfig `mapstructure:"encryption"`
+
+	// files 加载时实际读取的配置文件
+	files []string
+}
+
+// BackendConfig 配置组后端选择
+type BackendConfig struct {
+	// Type 后端类型：etcd（默认）或 file
+	Type string `mapstructure:"type"`
+	// Directory 文件后端的根目录，配置组映射为 <dir>/<app>/<env>/<group>.yaml
+	Directory string `mapstructure:"directory"`
+}
+
+// Validate 验证后端配置
+func (cfg *BackendConfig) Validate() error {
+	switch cfg.Type {
+	case "", BackendEtcd:
+		return nil
+	case BackendFile:
+		if cfg.Directory == "" {
+			return fmt.Errorf("file backend directory is required")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown config backend type: %s", cfg.Type)
+	}
+}
+
+// UseEtcd 配置组是否使用 etcd 后端
+func (cfg *BackendConfig) UseEtcd() bool {
+	return cfg.Type == "" || cfg.Type == BackendEtcd
 }
 
 // Validate 验证配置的有效性
@@ -21,34 +64,28 @@ func (cfg *AppConfig) Validate() error {
 	if cfg.Env == "" {
 		return fmt.Errorf("environment is required")
 	}
-	if len(cfg.Etcd.Endpoints) == 0 {
+	if err := cfg.Backend.Validate(); err != nil {
+		return err
+	}
+	if cfg.Backend.UseEtcd() && len(cfg.Etcd.Endpoints) == 0 {
 		return fmt.Errorf("etcd endpoints are required")
 	}
+	if err := cfg.Logger.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
 // NewAppConfig 从配置文件创建并加载主配置
-// 默认会查找当前目录及config子目录下的config.yaml文件
+// 依次使用 KMYH_CONFIG 环境变量指定的文件、当前目录及config子目录下的
+// config.yaml/yml/json/toml 文件，环境变量（KMYH_ 前缀）会覆盖配置文件中的值
 // 返回配置对象和可能的错误
 func NewAppConfig() (*AppConfig, error) {
-	v := viper.New()
-	v.SetConfigName("config")
-	v.SetConfigType("yaml")
-	v.AddConfigPath(".")
-	v.AddConfigPath("./config")
-
-	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	var cfg AppConfig
-	if err := v.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
-	}
-
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("config validation failed: %w", err)
-	}
+	return NewAppConfigWithOptions()
+}
 
-	return &cfg, nil
+// NewAppConfigFromFlags 从配置文件创建并加载主配置
+// fs 需事先通过 RegisterFlags 注册并完成解析，显式设置的参数优先级最高
+func NewAppConfigFromFlags(fs *pflag.FlagSet) (*AppConfig, error) {
+	return NewAppConfigWithOptions(WithFlags(fs))
 }
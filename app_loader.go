@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	// ConfigFileEnv 指定主配置文件路径的环境变量
+	ConfigFileEnv = EnvPrefix + "_CONFIG"
+	// includeKey 主配置文件中引用片段文件的键
+	includeKey = "include"
+)
+
+// supportedConfigExts 支持的配置文件扩展名，按查找顺序排列
+var supportedConfigExts = []string{".yaml", ".yml", ".json", ".toml"}
+
+type (
+	// AppConfigOption 主配置加载选项
+	AppConfigOption func(*appConfigOptions)
+
+	appConfigOptions struct {
+		file        string
+		searchPaths []string
+		flags       *pflag.FlagSet
+	}
+)
+
+// WithConfigFile 指定主配置文件路径，优先于 KMYH_CONFIG 环境变量和默认查找路径
+// 文件格式由扩展名决定，支持 YAML、JSON 和 TOML
+func WithConfigFile(path string) AppConfigOption {
+	return func(o *appConfigOptions) {
+		o.file = path
+	}
+}
+
+// WithSearchPaths 替换默认的配置文件查找目录（当前目录及 config 子目录）
+func WithSearchPaths(paths ...string) AppConfigOption {
+	return func(o *appConfigOptions) {
+		o.searchPaths = paths
+	}
+}
+
+// WithFlags 使用已通过 RegisterFlags 注册并完成解析的命令行参数覆盖配置
+func WithFlags(fs *pflag.FlagSet) AppConfigOption {
+	return func(o *appConfigOptions) {
+		o.flags = fs
+	}
+}
+
+// NewAppConfigWithOptions 按选项创建并加载主配置
+// 配置文件可通过 include 键引用其他片段文件（相对路径基于引用方所在目录），
+// 片段先于引用方合并，引用方中的值覆盖片段中的值
+func NewAppConfigWithOptions(opts ...AppConfigOption) (*AppConfig, error) {
+	o := appConfigOptions{searchPaths: []string{".", "./config"}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	file, err := o.resolveFile()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &configLoader{visiting: make(map[string]bool)}
+	settings := make(map[string]interface{})
+	if err := l.load(file, settings); err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, fmt.Errorf("failed to merge config files: %w", err)
+	}
+	bindEnv(v, reflect.TypeOf(AppConfig{}))
+	bindFlags(v, o.flags)
+
+	var cfg AppConfig
+	if err := unmarshalViper(v, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	cfg.files = l.files
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// LoadedFiles 返回加载主配置时实际读取的文件，按合并顺序排列
+func (cfg *AppConfig) LoadedFiles() []string {
+	return append([]string(nil), cfg.files...)
+}
+
+// resolveFile 确定主配置文件路径
+func (o *appConfigOptions) resolveFile() (string, error) {
+	file := o.file
+	if file == "" {
+		file = os.Getenv(ConfigFileEnv)
+	}
+	if file != "" {
+		if _, err := configType(file); err != nil {
+			return "", err
+		}
+		return file, nil
+	}
+
+	for _, dir := range o.searchPaths {
+		for _, ext := range supportedConfigExts {
+			path := filepath.Join(dir, "config"+ext)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("failed to read config file: config.{yaml,yml,json,toml} not found in %v", o.searchPaths)
+}
+
+// configLoader 递归加载配置文件及其引用的片段
+type configLoader struct {
+	files    []string
+	visiting map[string]bool
+}
+
+// load 读取 path 及其 include 片段并深度合并到 settings
+func (l *configLoader) load(path string, settings map[string]interface{}) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config file %s: %w", path, err)
+	}
+	if l.visiting[abs] {
+		return fmt.Errorf("config include cycle detected at %s", abs)
+	}
+	l.visiting[abs] = true
+	defer delete(l.visiting, abs)
+
+	typ, err := configType(abs)
+	if err != nil {
+		return err
+	}
+	v := viper.New()
+	v.SetConfigFile(abs)
+	v.SetConfigType(typ)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", abs, err)
+	}
+
+	own := v.AllSettings()
+	for _, inc := range v.GetStringSlice(includeKey) {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(abs), inc)
+		}
+		if err := l.load(inc, settings); err != nil {
+			return err
+		}
+	}
+	delete(own, includeKey)
+
+	mergeSettings(settings, own)
+	l.files = append(l.files, abs)
+	return nil
+}
+
+// configType 根据扩展名确定配置文件格式
+func configType(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, supported := range supportedConfigExts {
+		if ext == supported {
+			return strings.TrimPrefix(ext, "."), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported config file format %q: %s", ext, path)
+}
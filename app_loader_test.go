@@ -0,0 +1,137 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewAppConfigWithOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		main      string
+		wantFiles []string
+		wantErr   string
+		check     func(t *testing.T, cfg *AppConfig)
+	}{
+		{
+			name: "yaml with include",
+			files: map[string]string{
+				"config.yaml":      "include: [shared/etcd.yaml]\nname: shop\nenv: prod\netcd:\n  username: app\n",
+				"shared/etcd.yaml": "etcd:\n  endpoints: [a:2379]\n  username: shared\nlogger:\n  level: warn\n",
+			},
+			main:      "config.yaml",
+			wantFiles: []string{"shared/etcd.yaml", "config.yaml"},
+			check: func(t *testing.T, cfg *AppConfig) {
+				if !reflect.DeepEqual(cfg.Etcd.Endpoints, []string{"a:2379"}) || cfg.Etcd.Username != "app" || cfg.Logger.Level != "warn" {
+					t.Fatalf("got %+v", cfg)
+				}
+			},
+		},
+		{
+			name:      "json",
+			files:     map[string]string{"app.json": `{"name": "shop", "env": "prod", "backend": {"type": "file", "directory": "./data"}}`},
+			main:      "app.json",
+			wantFiles: []string{"app.json"},
+			check: func(t *testing.T, cfg *AppConfig) {
+				if cfg.Backend.Type != BackendFile || cfg.Backend.Directory != "./data" {
+					t.Fatalf("backend = %+v", cfg.Backend)
+				}
+			},
+		},
+		{
+			name:    "missing include",
+			files:   map[string]string{"config.yaml": "include: [missing.yaml]\nname: shop\n"},
+			main:    "config.yaml",
+			wantErr: "failed to read config file",
+		},
+		{
+			name: "nested includes override in order",
+			files: map[string]string{
+				"config.toml":          "include = [\"conf/base.yml\"]\nenv = \"prod\"\n[etcd]\nendpoints = [\"b:2379\"]\n",
+				"conf/base.yml":        "name: shop\nenv: dev\ninclude: [common/log.json]\n",
+				"conf/common/log.json": `{"logger": {"level": "error"}, "name": "common"}`,
+			},
+			main:      "config.toml",
+			wantFiles: []string{"conf/common/log.json", "conf/base.yml", "config.toml"},
+			check: func(t *testing.T, cfg *AppConfig) {
+				if cfg.AppName != "shop" || cfg.Env != "prod" || cfg.Logger.Level != "error" {
+					t.Fatalf("got name=%q env=%q level=%q", cfg.AppName, cfg.Env, cfg.Logger.Level)
+				}
+			},
+		},
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"a.yaml": "include: [b.yaml]\nname: shop\n",
+				"b.yaml": "include: [a.yaml]\n",
+			},
+			main:    "a.yaml",
+			wantErr: "include cycle",
+		},
+		{
+			name:    "unsupported format",
+			files:   map[string]string{"config.ini": "name=shop\n"},
+			main:    "config.ini",
+			wantErr: "unsupported config file format",
+		},
+		{
+			name:    "validation",
+			files:   map[string]string{"config.yaml": "name: shop\nenv: prod\n"},
+			main:    "config.yaml",
+			wantErr: "etcd endpoints are required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for rel, content := range tt.files {
+				writeTestFile(t, filepath.Join(dir, rel), content)
+			}
+
+			cfg, err := NewAppConfigWithOptions(WithConfigFile(filepath.Join(dir, tt.main)))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want []string
+			for _, f := range tt.wantFiles {
+				want = append(want, filepath.Join(dir, f))
+			}
+			if got := cfg.LoadedFiles(); !reflect.DeepEqual(got, want) {
+				t.Fatalf("LoadedFiles = %v, want %v", got, want)
+			}
+			if tt.check != nil {
+				tt.check(t, cfg)
+			}
+		})
+	}
+}
+
+func TestNewAppConfigSearchPaths(t *testing.T) {
+	t.Setenv(ConfigFileEnv, "")
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "config", "config.toml"), "name = \"shop\"\nenv = \"prod\"\n[etcd]\nendpoints = [\"a:2379\"]\n")
+	writeTestFile(t, filepath.Join(dir, "config", "config.json"), `{"name": "json", "env": "prod", "etcd": {"endpoints": ["a:2379"]}}`)
+
+	cfg, err := NewAppConfigWithOptions(WithSearchPaths(dir, filepath.Join(dir, "config")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同一目录下按 yaml、yml、json、toml 的顺序查找
+	if cfg.AppName != "json" {
+		t.Fatalf("loaded %v, want config.json", cfg.LoadedFiles())
+	}
+
+	if _, err := NewAppConfigWithOptions(WithSearchPaths(filepath.Join(dir, "missing"))); err == nil {
+		t.Fatal("expected error when no config file is found")
+	}
+}
@@ -0,0 +1,283 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+// watchDebounce 合并短时间内连续的 etcd 变更事件，只通知一次
+const watchDebounce = 100 * time.Millisecond
+
+// ErrContentDeleted 配置组的内容键已全部删除，配置组保留上一个有效配置
+var ErrContentDeleted = errors.New("config content deleted")
+
+const (
+	// BackendEtcd 基于 etcd 的配置组后端（默认）
+	BackendEtcd = "etcd"
+	// BackendFile 基于本地文件的配置组后端，适用于本地开发
+	BackendFile = "file"
+)
+
+// Backend 配置组存储后端
+// ConfigManager 通过后端读取与监听配置组，校验、快照与通知逻辑与后端无关
+type Backend interface {
+	// Key 返回配置组在后端中的唯一标识
+	Key(app, env, group string) string
+	// Load 读取配置组内容，返回解析后的配置、内容的修改版本号和读取时的后端版本号
+	// 读取时的版本号作为 WatchOptions.Revision 的起点，不区分两者的后端可返回相同的值
+	Load(key string) (v *viper.Viper, revision, readRevision int64, err error)
+	// Watch 监听配置组变更，直到 ctx 结束
+	Watch(ctx context.Context, key string, opts WatchOptions)
+}
+
+// WatchOptions 后端监听参数
+type WatchOptions struct {
+	// Revision Load 返回的读取时版本号，仅接收其后的变更，0 表示从当前开始
+	Revision int64
+	// Apply 配置变更时以新的配置和修改版本号调用
+	Apply func(candidate *viper.Viper, revision int64)
+	// State 监听状态变化时调用，可为 nil
+	State func(state WatchState, err error)
+	// SecretPatterns 全局模式之外的敏感键名模式，记录变更日志时隐藏匹配的值
+	SecretPatterns []string
+	// SecretPaths 返回配置组已绑定结构体中 secret:"true" 字段的点分路径，可为 nil
+	// 映射的键以 "*" 表示，记录变更日志时隐藏这些路径及其子树的值
+	SecretPaths func() []string
+}
+
+// secretPaths 返回已绑定的敏感字段路径
+func (o WatchOptions) secretPaths() []string {
+	if o.SecretPaths == nil {
+		return nil
+	}
+	return o.SecretPaths()
+}
+
+// setState 通知监听状态变化
+func (o WatchOptions) setState(state WatchState, err error) {
+	if o.State != nil {
+		o.State(state, err)
+	}
+}
+
+// etcdBackend 基于 etcd 的配置组后端
+type etcdBackend struct {
+	client *clientv3.Client
+	cfg    EtcdConfig
+	logger *zap.SugaredLogger
+}
+
+// NewEtcdBackend 创建基于 etcd 的配置组后端
+func NewEtcdBackend(client *clientv3.Client, cfg EtcdConfig, logger *zap.Logger) Backend {
+	return &etcdBackend{
+		client: client,
+		cfg:    cfg,
+		logger: subLogger(logger, "[etcd backend]").Sugar(),
+	}
+}
+
+// Key 返回配置组在 etcd 中的前缀
+func (b *etcdBackend) Key(app, env, group string) string {
+	return etcdGroupKey(b.cfg.Prefix, app, env, group)
+}
+
+// Load 通过注入的 etcd 客户端一次读取配置组的全部内容键，并按存储布局解析
+// 与监听共用同一客户端，认证、TLS 与多节点故障切换对读取同样生效
+// 监听从响应头中的集群版本号开始，避免从久远的内容修改版本号恢复时该版本已被压缩
+func (b *etcdBackend) Load(key string) (*viper.Viper, int64, int64, error) {
+	ctx, cancel := requestContext(b.cfg)
+	defer cancel()
+
+	resp, err := b.client.Get(ctx, key+"/"+contentName, clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to read config %s: %w", key, err)
+	}
+
+	kvs := make(map[string][]byte, len(resp.Kvs))
+	revisions := make(map[string]int64, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		kvs[string(kv.Key)] = kv.Value
+		revisions[string(kv.Key)] = kv.ModRevision
+	}
+
+	v, err := decodeGroup(key, kvs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return v, contentRevision(key, revisions), resp.Header.Revision, nil
+}
+
+// Watch 监听配置组内容键（content.* 及拆分布局下的 content/ 子键）的变更事件
+// 事件中已包含新值，直接在本地维护的内容键副本上更新并解析，不再重新读取
+// 监听中断时从最后看到的版本号继续，版本已被压缩时完整重新读取，并按退避间隔重试
+// 监听成功建立后退避间隔恢复为初始值
+func (b *etcdBackend) Watch(ctx context.Context, key string, opts WatchOptions) {
+	log := b.logger.With(zap.String("group", key))
+	defer opts.setState(WatchStateStopped, nil)
+
+	lastRevision := opts.Revision
+	backoff := retryMinBackoff
+	for {
+		watching, err := b.watchOnce(ctx, key, &lastRevision, opts, log)
+		if ctx.Err() != nil {
+			return
+		}
+		if watching {
+			backoff = retryMinBackoff
+		}
+
+		opts.setState(WatchStateReconnecting, err)
+		log.Warn("配置监听中断，准备重连",
+			zap.Int64("last_revision", lastRevision), zap.Duration("backoff", backoff), zap.Error(err))
+		if !sleepContext(ctx, backoff) {
+			return
+		}
+		backoff = min(backoff*2, retryMaxBackoff)
+	}
+}
+
+// watchOnce 读取内容键在 lastRevision 时的副本并从其后开始监听，直到监听中断
+// 防抖窗口内的连续事件合并为一次 Apply，返回监听是否曾成功建立
+func (b *etcdBackend) watchOnce(ctx context.Context, key string, lastRevision *int64,
+	opts WatchOptions, log *zap.SugaredLogger) (bool, error) {
+	watchKey := key + "/" + contentName
+
+	kvs, revision, err := b.contentSnapshot(ctx, watchKey, *lastRevision)
+	if errors.Is(err, rpctypes.ErrCompacted) {
+		// 已读取的版本已被压缩，期间的变更无法回放，按当前内容重新应用
+		log.Warn("监听版本已被压缩，重新读取完整配置", zap.Int64("last_revision", *lastRevision))
+		if kvs, revision, err = b.contentSnapshot(ctx, watchKey, 0); err == nil {
+			b.applyContent(key, kvs, revision, opts, log)
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	*lastRevision = revision
+
+	// 要求存在 leader，发生网络分区时监听会被关闭而不是静默挂起
+	watchChan := b.client.Watch(clientv3.WithRequireLeader(ctx), watchKey,
+		clientv3.WithPrefix(), clientv3.WithRev(revision+1))
+
+	log.Info("开始监听配置变更", zap.String("watch_key", watchKey), zap.Int64("from_revision", revision))
+	opts.setState(WatchStateWatching, nil)
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	// pending 防抖窗口内最后一个事件的版本号，0 表示没有待应用的变更
+	var pending int64
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case <-debounce.C:
+			b.applyContent(key, kvs, pending, opts, log)
+			*lastRevision = pending
+			pending = 0
+		case watchResp, ok := <-watchChan:
+			if !ok {
+				b.flushPending(key, kvs, &pending, lastRevision, opts, log)
+				return true, fmt.Errorf("watch channel closed")
+			}
+			if watchResp.CompactRevision != 0 {
+				b.flushPending(key, kvs, &pending, lastRevision, opts, log)
+				return true, fmt.Errorf("watch revision compacted at %d: %w",
+					watchResp.CompactRevision, rpctypes.ErrCompacted)
+			}
+			if err := watchResp.Err(); err != nil {
+				b.flushPending(key, kvs, &pending, lastRevision, opts, log)
+				return true, err
+			}
+
+			for _, event := range watchResp.Events {
+				eventKey := string(event.Kv.Key)
+				switch event.Type {
+				case clientv3.EventTypeDelete:
+					log.Info("配置键已删除", zap.String("key", eventKey))
+					delete(kvs, eventKey)
+				default:
+					log.Info("配置变更事件",
+						zap.String("key", eventKey),
+						zap.String("value", redactContent(key, eventKey, event.Kv.Value, opts.SecretPatterns, opts.secretPaths())))
+					kvs[eventKey] = event.Kv.Value
+				}
+				pending = event.Kv.ModRevision
+			}
+			if len(watchResp.Events) > 0 {
+				debounce.Reset(watchDebounce)
+			}
+		}
+	}
+}
+
+// flushPending 监听中断前应用防抖窗口内尚未应用的变更
+func (b *etcdBackend) flushPending(key string, kvs map[string][]byte, pending, lastRevision *int64,
+	opts WatchOptions, log *zap.SugaredLogger) {
+	if *pending == 0 {
+		return
+	}
+	b.applyContent(key, kvs, *pending, opts, log)
+	*lastRevision = *pending
+	*pending = 0
+}
+
+// applyContent 解析内容键副本并应用，解析失败时保留当前配置
+// 内容键已全部删除时不应用，保留上一个有效配置与本地快照，并将监听状态置为 stale；写入空内容才会清空配置组
+func (b *etcdBackend) applyContent(key string, kvs map[string][]byte, revision int64,
+	opts WatchOptions, log *zap.SugaredLogger) {
+	if len(kvs) == 0 {
+		log.Error("配置组内容已全部删除，保留上一个有效配置", zap.Int64("revision", revision))
+		opts.setState(WatchStateStale, fmt.Errorf("%w at revision %d", ErrContentDeleted, revision))
+		return
+	}
+	candidate, err := decodeGroup(key, kvs)
+	if err != nil {
+		log.Error("解析配置变更失败", zap.Int64("revision", revision), zap.Error(err))
+		return
+	}
+	opts.Apply(candidate, revision)
+	opts.setState(WatchStateWatching, nil)
+}
+
+// contentSnapshot 读取内容键在指定版本的值，revision 为 0 时读取当前值
+// 返回内容键副本与读取时的集群版本号
+func (b *etcdBackend) contentSnapshot(ctx context.Context, watchKey string, revision int64) (map[string][]byte, int64, error) {
+	timeout := b.cfg.DialTimeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	getOpts := []clientv3.OpOption{clientv3.WithPrefix()}
+	if revision > 0 {
+		getOpts = append(getOpts, clientv3.WithRev(revision))
+	}
+	resp, err := b.client.Get(reqCtx, watchKey, getOpts...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	kvs := make(map[string][]byte, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		kvs[string(kv.Key)] = kv.Value
+	}
+	if revision > 0 {
+		return kvs, revision, nil
+	}
+	return kvs, resp.Header.Revision, nil
+}
+
+// etcdGroupKey 返回配置组在 etcd 中的前缀
+func etcdGroupKey(prefix, app, env, group string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", prefix, app, env, group)
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// fileDebounce 合并编辑器保存文件时产生的连续事件
+const fileDebounce = 100 * time.Millisecond
+
+// fileBackend 基于本地文件的配置组后端
+// 配置组映射为 <dir>/<app>/<env>/<group>.yaml
+type fileBackend struct {
+	dir    string
+	logger *zap.SugaredLogger
+}
+
+// NewFileBackend 创建基于本地文件的配置组后端
+func NewFileBackend(dir string, logger *zap.Logger) (Backend, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("file backend directory is required")
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve file backend directory: %w", err)
+	}
+	return &fileBackend{
+		dir:    abs,
+		logger: subLogger(logger, "[file backend]").Sugar(),
+	}, nil
+}
+
+// Key 返回配置组文件路径
+func (b *fileBackend) Key(app, env, group string) string {
+	return filepath.Join(b.dir, app, env, group+".yaml")
+}
+
+// Load 读取配置组文件，以文件修改时间作为版本号
+func (b *fileBackend) Load(key string) (*viper.Viper, int64, int64, error) {
+	info, err := os.Stat(key)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to stat config file: %w", err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(key)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to read config file %s: %w", key, err)
+	}
+	revision := info.ModTime().UnixNano()
+	return v, revision, revision, nil
+}
+
+// Watch 监听配置组文件所在目录，文件写入、重命名或删除后重新读取
+// 监听目录而非文件本身，以兼容编辑器先写临时文件再重命名的保存方式
+func (b *fileBackend) Watch(ctx context.Context, key string, opts WatchOptions) {
+	log := b.logger.With(zap.String("group", key))
+	defer opts.setState(WatchStateStopped, nil)
+
+	dir := filepath.Dir(key)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Error("创建配置目录失败", zap.Error(err))
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Error("创建文件监听失败", zap.Error(err))
+		return
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(dir); err != nil {
+		log.Error("添加文件监听失败", zap.String("dir", dir), zap.Error(err))
+		return
+	}
+
+	log.Info("开始监听配置变更", zap.String("watch_file", key))
+	opts.setState(WatchStateWatching, nil)
+
+	timer := time.NewTimer(fileDebounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != key {
+				continue
+			}
+			log.Debug("配置文件事件", zap.String("op", event.Op.String()))
+			timer.Reset(fileDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Warn("文件监听错误", zap.Error(err))
+		case <-timer.C:
+			candidate, revision, _, err := b.Load(key)
+			if err != nil {
+				log.Error("重新读取配置失败", zap.Error(err))
+				continue
+			}
+			log.Info("配置文件已变更", zap.Int64("revision", revision))
+			opts.Apply(candidate, revision)
+		}
+	}
+}
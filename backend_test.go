@@ -0,0 +1,170 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+func TestApplyContent(t *testing.T) {
+	const base = "/configs/app/prod/database"
+
+	tests := []struct {
+		name      string
+		kvs       map[string][]byte
+		wantApply bool
+		wantState WatchState
+		wantErr   error
+	}{
+		{
+			name:      "content applied",
+			kvs:       map[string][]byte{base + "/content.yaml": []byte("host: a\n")},
+			wantApply: true,
+			wantState: WatchStateWatching,
+		},
+		{
+			name:      "explicit empty content clears group",
+			kvs:       map[string][]byte{base + "/content.yaml": []byte("")},
+			wantApply: true,
+			wantState: WatchStateWatching,
+		},
+		{
+			name:      "all content keys deleted",
+			kvs:       map[string][]byte{},
+			wantState: WatchStateStale,
+			wantErr:   ErrContentDeleted,
+		},
+		{
+			name: "invalid content",
+			kvs:  map[string][]byte{base + "/content.yaml": []byte("host: [a\n")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &etcdBackend{logger: zap.NewNop().Sugar()}
+			applied := false
+			var states []WatchState
+			var stateErr error
+			opts := WatchOptions{
+				Apply: func(candidate *viper.Viper, revision int64) {
+					applied = true
+					if revision != 42 {
+						t.Errorf("revision = %d, want 42", revision)
+					}
+				},
+				State: func(state WatchState, err error) {
+					states = append(states, state)
+					stateErr = err
+				},
+			}
+
+			b.applyContent(base, tt.kvs, 42, opts, b.logger)
+			if applied != tt.wantApply {
+				t.Fatalf("applied = %v, want %v", applied, tt.wantApply)
+			}
+			if tt.wantApply || tt.wantErr != nil {
+				if len(states) != 1 || states[0] != tt.wantState {
+					t.Fatalf("states = %v, want [%v]", states, tt.wantState)
+				}
+			} else if len(states) != 0 {
+				t.Fatalf("unexpected state change %v", states)
+			}
+			if tt.wantErr != nil && !errors.Is(stateErr, tt.wantErr) {
+				t.Fatalf("state error = %v, want %v", stateErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeBackend 由测试驱动变更的配置组后端，Watch 将监听参数交给测试后阻塞到 ctx 结束
+type fakeBackend struct {
+	kvs     map[string][]byte
+	watches chan WatchOptions
+}
+
+func (b *fakeBackend) Key(app, env, group string) string {
+	return "/configs/" + app + "/" + env + "/" + group
+}
+
+func (b *fakeBackend) Load(key string) (*viper.Viper, int64, int64, error) {
+	v, err := decodeGroup(key, b.kvs)
+	return v, 1, 1, err
+}
+
+func (b *fakeBackend) Watch(ctx context.Context, key string, opts WatchOptions) {
+	defer opts.setState(WatchStateStopped, nil)
+	b.watches <- opts
+	<-ctx.Done()
+}
+
+func TestManagerContentDeletedAndDebounce(t *testing.T) {
+	const base = "/configs/app/prod/database"
+	fake := &fakeBackend{
+		kvs:     map[string][]byte{base + "/content.yaml": []byte("host: a\n")},
+		watches: make(chan WatchOptions, 1),
+	}
+	m := NewConfigManagerWithBackend(fake, zap.NewNop(), &AppConfig{})
+	t.Cleanup(func() { _ = m.Stop(context.Background()) })
+
+	g := m.GetGroup("app", "prod", "database")
+	var opts WatchOptions
+	select {
+	case opts = <-fake.watches:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch not started")
+	}
+	var changes atomic.Int32
+	g.OnChange(func() { changes.Add(1) })
+	errs := make(chan error, 4)
+	g.OnError(func(err error) { errs <- err })
+
+	etcd := &etcdBackend{logger: zap.NewNop().Sugar()}
+
+	// 内容键全部删除：保留上一个有效配置，状态为 stale 并上报错误
+	etcd.applyContent(base, map[string][]byte{}, 5, opts, etcd.logger)
+	if got := g.GetString("host"); got != "a" {
+		t.Fatalf("host after delete = %q, want a", got)
+	}
+	if state := g.WatchState(); state != WatchStateStale || m.Healthy() == nil {
+		t.Fatalf("state after delete = %v", state)
+	}
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrContentDeleted) {
+			t.Fatalf("OnError = %v, want ErrContentDeleted", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnError not called for deleted content")
+	}
+
+	// 防抖窗口内的多次变更合并为一次应用，状态恢复为 watching
+	kvs := make(map[string][]byte)
+	var pending, last int64
+	for i, host := range []string{"b", "c", "d"} {
+		kvs[base+"/content.yaml"] = []byte("host: " + host + "\n")
+		pending = int64(6 + i)
+	}
+	etcd.flushPending(base, kvs, &pending, &last, opts, etcd.logger)
+	if got := g.GetString("host"); got != "d" || last != 8 || pending != 0 {
+		t.Fatalf("after flush host = %q, last = %d, pending = %d", got, last, pending)
+	}
+	if state := g.WatchState(); state != WatchStateWatching {
+		t.Fatalf("state after recreate = %v", state)
+	}
+	etcd.flushPending(base, kvs, &pending, &last, opts, etcd.logger)
+	eventually(t, func() bool { return changes.Load() == 1 }, "one change callback")
+
+	// 显式写入空内容清空配置组
+	etcd.applyContent(base, map[string][]byte{base + "/content.yaml": nil}, 9, opts, etcd.logger)
+	if g.Get("host") != nil {
+		t.Fatalf("host after explicit empty content = %v", g.Get("host"))
+	}
+	if state := g.WatchState(); state != WatchStateWatching {
+		t.Fatalf("state after explicit empty content = %v", state)
+	}
+}
@@ -1,10 +1,17 @@
 package config
 
+import "fmt"
+
 type (
 	SuperAdminConfig struct {
 		Username string `mapstructure:"username"`
 		Email    string `mapstructure:"email"`
-		Password string `mapstructure:"password"`
+		Password string `mapstructure:"password" secret:"true"`
 		RoleID   string `mapstructure:"role_id"`
 	}
 )
+
+// Format 格式化输出时隐藏敏感字段
+func (cfg SuperAdminConfig) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, cfg)
+}
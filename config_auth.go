@@ -1,11 +1,18 @@
 package config
 
+import "fmt"
+
 type AuthConfig struct {
 	Enable             bool     `mapstructure:"Enable"`
 	TokenExpired       string   `mapstructure:"TokenExpired"` //time.ParseDuration(),示例：1d,10m,50s.....
 	IgnorePathPrefixes []string `mapstructure:"IgnorePathPrefixes"`
-	JWTSigningKey      string   `mapstructure:"JWTSigningKey"`
+	JWTSigningKey      string   `mapstructure:"JWTSigningKey" secret:"true"`
 	Issuer             string   `mapstructure:"Issuer"`
 	VerifyModes        []string `mapstructure:"VerifyModes"`   // 验证模式: captcha, sms, email
 	ExpireMinutes      int      `mapstructure:"ExpireMinutes"` // 验证码有效期（分钟）
 }
+
+// Format 格式化输出时隐藏敏感字段
+func (cfg AuthConfig) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, cfg)
+}
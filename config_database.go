@@ -13,7 +13,7 @@ type DatabaseConfig struct {
 	Host        string `mapstructure:"host"`
 	Port        int    `mapstructure:"port"`
 	Username    string `mapstructure:"username"`
-	Password    string `mapstructure:"password"`
+	Password    string `mapstructure:"password" secret:"true"`
 	TablePrefix string `mapstructure:"table_prefix"`
 	Parameters  string `mapstructure:"parameters"`
 
@@ -87,3 +87,8 @@ func (cfg *DatabaseConfig) parseParameters() url.Values {
 
 	return values
 }
+
+// Format 格式化输出时隐藏敏感字段
+func (cfg DatabaseConfig) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, cfg)
+}
@@ -5,7 +5,7 @@ import "fmt"
 // DifyConfig Dify AI平台配置
 type DifyConfig struct {
 	BaseURL       string `mapstructure:"base_url"`
-	APIKey        string `mapstructure:"api_key"`
+	APIKey        string `mapstructure:"api_key" secret:"true"`
 	CachePeriod   string `mapstructure:"cache_period"`
 	DefaultPrompt string `mapstructure:"default_prompt"`
 	BotType       string `mapstructure:"bot_type"`
@@ -22,3 +22,8 @@ func (cfg *DifyConfig) Validate() error {
 	}
 	return nil
 }
+
+// Format 格式化输出时隐藏敏感字段
+func (cfg DifyConfig) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, cfg)
+}
@@ -1,13 +1,20 @@
 package config
 
+import "fmt"
+
 type EmailConfig struct {
 	SMTPHost              string  `mapstructure:"smtp_host"`
 	SMTPPort              int     `mapstructure:"smtp_port"`
 	Username              string  `mapstructure:"username"`
-	Password              string  `mapstructure:"password"`
+	Password              string  `mapstructure:"password" secret:"true"`
 	FromAddress           string  `mapstructure:"from_address"`
 	UseTLS                bool    `mapstructure:"use_tls"`
 	TLSInsecureSkipVerify bool    `mapstructure:"tls_insecure_skip_verify"`
 	RateLimitPerSecond    float64 `mapstructure:"rate_limit_per_second"`
 	RateLimitBurst        int     `mapstructure:"rate_limit_burst"`
 }
+
+// Format 格式化输出时隐藏敏感字段
+func (cfg EmailConfig) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, cfg)
+}
@@ -13,15 +13,43 @@ type EtcdConfig struct {
 	// Username 认证用户名
 	Username string `yaml:"username,omitempty" mapstructure:"username"`
 	// Password 认证密码
-	Password string `yaml:"password,omitempty" mapstructure:"password"`
-	// DialTimeout 连接超时时间
-	DialTimeout time.Duration `yaml:"dial_timeout" mapstructure:"dial_timeout"`
+	Password string `yaml:"password,omitempty" mapstructure:"password" secret:"true"`
+	// DialTimeout 连接超时时间，默认 5s
+	DialTimeout time.Duration `yaml:"dial_timeout" mapstructure:"dial_timeout" default:"5s"`
 	// TLS TLS安全连接配置
 	TLS *TLSConfig `yaml:"tls,omitempty" mapstructure:"tls"`
 	// Prefix 配置键的前缀
 	Prefix string `yaml:"prefix" mapstructure:"prefix"`
+	// CacheDir 配置组快照的本地缓存目录，为空时不启用
+	// etcd 不可用时从快照启动，连接恢复后自动与远程配置同步
+	CacheDir string `yaml:"cache_dir,omitempty" mapstructure:"cache_dir"`
+	// HistoryLimit 每个配置组保留的审计历史条数，默认 50
+	HistoryLimit int `yaml:"history_limit,omitempty" mapstructure:"history_limit"`
+	// KeepAliveTime 客户端心跳间隔，默认 5s
+	KeepAliveTime time.Duration `yaml:"keepalive_time,omitempty" mapstructure:"keepalive_time"`
+	// KeepAliveTimeout 心跳响应超时时间，默认 5s
+	KeepAliveTimeout time.Duration `yaml:"keepalive_timeout,omitempty" mapstructure:"keepalive_timeout"`
+	// AutoSyncInterval 自动同步集群节点列表的间隔，默认 30s，负数表示不同步
+	AutoSyncInterval time.Duration `yaml:"auto_sync_interval,omitempty" mapstructure:"auto_sync_interval"`
+	// BackoffMaxDelay 连接重试的最大间隔，默认 3s
+	BackoffMaxDelay time.Duration `yaml:"backoff_max_delay,omitempty" mapstructure:"backoff_max_delay"`
+	// MaxCallSendMsgSize 单次请求的最大发送字节数，0 使用客户端默认值
+	MaxCallSendMsgSize int `yaml:"max_call_send_msg_size,omitempty" mapstructure:"max_call_send_msg_size"`
+	// MaxCallRecvMsgSize 单次请求的最大接收字节数，0 使用客户端默认值
+	MaxCallRecvMsgSize int `yaml:"max_call_recv_msg_size,omitempty" mapstructure:"max_call_recv_msg_size"`
+	// RejectOldCluster 拒绝连接版本过旧的集群
+	RejectOldCluster bool `yaml:"reject_old_cluster,omitempty" mapstructure:"reject_old_cluster"`
+	// StartDegraded 启动时 etcd 不可用不视为失败，配置组从快照启动并在连接恢复后同步
+	StartDegraded bool `yaml:"start_degraded,omitempty" mapstructure:"start_degraded"`
 }
 
+const (
+	defaultKeepAliveTime    = 5 * time.Second
+	defaultKeepAliveTimeout = 5 * time.Second
+	defaultAutoSyncInterval = 30 * time.Second
+	defaultBackoffMaxDelay  = 3 * time.Second
+)
+
 // Validate 验证etcd配置
 func (cfg *EtcdConfig) Validate() error {
 	if len(cfg.Endpoints) == 0 {
@@ -35,15 +63,48 @@ func (cfg *EtcdConfig) Validate() error {
 	if cfg.DialTimeout <= 0 {
 		return fmt.Errorf("dial timeout must be greater than 0")
 	}
+	if cfg.HistoryLimit < 0 {
+		return fmt.Errorf("history limit cannot be negative")
+	}
+	if cfg.KeepAliveTime < 0 || cfg.KeepAliveTimeout < 0 || cfg.BackoffMaxDelay < 0 {
+		return fmt.Errorf("keepalive and backoff durations cannot be negative")
+	}
+	if cfg.MaxCallSendMsgSize < 0 || cfg.MaxCallRecvMsgSize < 0 {
+		return fmt.Errorf("max call message sizes cannot be negative")
+	}
+	if cfg.TLS != nil {
+		if err := cfg.TLS.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// durationOrDefault 未配置时返回默认值
+func durationOrDefault(d, def time.Duration) time.Duration {
+	if d == 0 {
+		return def
+	}
+	return d
+}
+
 // TLSConfig 包含TLS安全连接的配置参数
 type TLSConfig struct {
 	// CertFile 证书文件路径
 	CertFile string `yaml:"cert_file" mapstructure:"cert_file"`
 	// KeyFile 私钥文件路径
 	KeyFile string `yaml:"key_file" mapstructure:"key_file"`
-	// CAFile CA证书文件路径
+	// CAFile CA证书文件路径，为空时使用系统证书池
 	CAFile string `yaml:"ca_file" mapstructure:"ca_file"`
+	// ServerName 校验服务端证书时使用的主机名，为空时使用连接地址
+	ServerName string `yaml:"server_name,omitempty" mapstructure:"server_name"`
+	// MinVersion TLS 最低版本，支持 1.2、1.3，默认 1.2
+	MinVersion string `yaml:"min_version,omitempty" mapstructure:"min_version"`
+	// InsecureSkipVerify 跳过服务端证书校验，仅用于测试环境
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty" mapstructure:"insecure_skip_verify"`
+}
+
+// Format 格式化输出时隐藏敏感字段
+func (cfg EtcdConfig) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, cfg)
 }
@@ -4,7 +4,7 @@ import "fmt"
 
 // FuiouConfig 富友支付配置
 type FuiouConfig struct {
-	MchntKey string `mapstructure:"mchnt_key"`
+	MchntKey string `mapstructure:"mchnt_key" secret:"true"`
 }
 
 // Validate 验证富友支付配置
@@ -14,3 +14,8 @@ func (cfg *FuiouConfig) Validate() error {
 	}
 	return nil
 }
+
+// Format 格式化输出时隐藏敏感字段
+func (cfg FuiouConfig) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, cfg)
+}
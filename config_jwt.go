@@ -1,5 +1,12 @@
 package config
 
+import "fmt"
+
 type JWTConfig struct {
-	SigningKey string `mapstructure:"SigningKey"` // JWT签名密钥
+	SigningKey string `mapstructure:"SigningKey" secret:"true"` // JWT签名密钥
+}
+
+// Format 格式化输出时隐藏敏感字段
+func (cfg JWTConfig) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, cfg)
 }
@@ -1,5 +1,31 @@
 package config
 
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// LogFileModeLevel 按级别写入 debug/info/warn/error 四个日志文件（默认）
+	LogFileModeLevel = "level"
+	// LogFileModeSingle 所有级别写入同一个日志文件
+	LogFileModeSingle = "single"
+
+	// LogDirRelativeCwd 相对日志目录基于当前工作目录解析（默认）
+	LogDirRelativeCwd = "cwd"
+	// LogDirRelativeExecutable 相对日志目录基于可执行文件所在目录解析
+	LogDirRelativeExecutable = "executable"
+
+	// LogConsoleStdout 控制台输出到标准输出（默认）
+	LogConsoleStdout = "stdout"
+	// LogConsoleStderr 控制台输出到标准错误
+	LogConsoleStderr = "stderr"
+	// LogConsoleNone 不输出到控制台
+	LogConsoleNone = "none"
+)
+
 // LogConfig 日志配置
 type LogConfig struct {
 	Level       string `mapstructure:"level"`
@@ -7,4 +33,88 @@ type LogConfig struct {
 	ToFile      bool   `mapstructure:"to_file"`
 	Directory   string `mapstructure:"directory"`
 	Development bool   `mapstructure:"development"`
+	// FileMode 日志文件模式：level（按级别拆分，默认）或 single（单个文件）
+	FileMode string `mapstructure:"file_mode"`
+	// FileName single 模式下的日志文件名（不含扩展名），默认 app
+	FileName string `mapstructure:"file_name"`
+	// RelativeTo 相对日志目录的解析基准：cwd（默认）或 executable，绝对路径不受影响
+	RelativeTo string `mapstructure:"relative_to"`
+	// Console 控制台输出：stdout（默认）、stderr 或 none
+	Console string `mapstructure:"console"`
+	// Rotation 日志文件滚动设置，为空时使用默认值
+	Rotation *LogRotationConfig `mapstructure:"rotation"`
+	// Sampling 日志采样设置，为空时不采样
+	Sampling *LogSamplingConfig `mapstructure:"sampling"`
+}
+
+// LogRotationConfig 日志文件滚动设置
+type LogRotationConfig struct {
+	// MaxSize 单个日志文件的最大大小（MB），0 表示 100
+	MaxSize int `mapstructure:"max_size"`
+	// MaxAge 旧日志文件的保留天数，0 表示不按时间清理
+	MaxAge int `mapstructure:"max_age"`
+	// MaxBackups 旧日志文件的保留个数，0 表示不按个数清理
+	MaxBackups int `mapstructure:"max_backups"`
+	// Compress 是否压缩旧日志文件
+	Compress bool `mapstructure:"compress"`
+}
+
+// defaultLogRotation 未配置滚动设置时的默认值
+var defaultLogRotation = LogRotationConfig{
+	MaxSize:    100,
+	MaxAge:     7,
+	MaxBackups: 14,
+	Compress:   true,
+}
+
+// LogSamplingConfig 日志采样设置
+// 每个 Tick 内相同级别与消息的日志先输出 Initial 条，此后每 Thereafter 条输出一条
+type LogSamplingConfig struct {
+	Initial    int           `mapstructure:"initial"`
+	Thereafter int           `mapstructure:"thereafter"`
+	Tick       time.Duration `mapstructure:"tick"`
+}
+
+// Validate 验证日志配置
+func (cfg *LogConfig) Validate() error {
+	if cfg.Level != "" {
+		if _, err := zapcore.ParseLevel(cfg.Level); err != nil {
+			return fmt.Errorf("invalid log level: %s", cfg.Level)
+		}
+	}
+	switch cfg.Format {
+	case "", "json", "console":
+	default:
+		return fmt.Errorf("unsupported log format: %s", cfg.Format)
+	}
+	switch cfg.FileMode {
+	case "", LogFileModeLevel, LogFileModeSingle:
+	default:
+		return fmt.Errorf("unsupported log file mode: %s", cfg.FileMode)
+	}
+	switch cfg.RelativeTo {
+	case "", LogDirRelativeCwd, LogDirRelativeExecutable:
+	default:
+		return fmt.Errorf("unsupported log relative_to: %s", cfg.RelativeTo)
+	}
+	switch cfg.Console {
+	case "", LogConsoleStdout, LogConsoleStderr, LogConsoleNone:
+	default:
+		return fmt.Errorf("unsupported log console output: %s", cfg.Console)
+	}
+	if !cfg.ToFile && cfg.Console == LogConsoleNone {
+		return fmt.Errorf("log output is disabled: enable to_file or console")
+	}
+	if r := cfg.Rotation; r != nil && (r.MaxSize < 0 || r.MaxAge < 0 || r.MaxBackups < 0) {
+		return fmt.Errorf("log rotation settings cannot be negative")
+	}
+	if s := cfg.Sampling; s != nil {
+		if s.Initial <= 0 {
+			return fmt.Errorf("log sampling initial must be greater than 0")
+		}
+		if s.Thereafter < 0 || s.Tick < 0 {
+			return fmt.Errorf("log sampling thereafter and tick cannot be negative")
+		}
+	}
+	return nil
 }
@@ -6,7 +6,7 @@ import "fmt"
 type NatsConfig struct {
 	Address    string   `mapstructure:"address"`
 	Username   string   `mapstructure:"username"`
-	Password   string   `mapstructure:"password"`
+	Password   string   `mapstructure:"password" secret:"true"`
 	Subscribes []string `mapstructure:"subscribes"`
 }
 
@@ -17,3 +17,8 @@ func (cfg *NatsConfig) Validate() error {
 	}
 	return nil
 }
+
+// Format 格式化输出时隐藏敏感字段
+func (cfg NatsConfig) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, cfg)
+}
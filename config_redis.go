@@ -5,7 +5,7 @@ import "fmt"
 type RedisConfig struct {
 	Host      string `mapstructure:"host"`
 	Port      int    `mapstructure:"port"`
-	Password  string `mapstructure:"password"`
+	Password  string `mapstructure:"password" secret:"true"`
 	KeyPrefix string `mapstructure:"key_prefix"`
 	MainDBId  int    `mapstructure:"main_db_id"`
 }
@@ -13,3 +13,8 @@ type RedisConfig struct {
 func (cfg *RedisConfig) Addr() string {
 	return fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
 }
+
+// Format 格式化输出时隐藏敏感字段
+func (cfg RedisConfig) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, cfg)
+}
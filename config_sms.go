@@ -1,11 +1,18 @@
 package config
 
+import "fmt"
+
 type AliyunSMSConfig struct {
 	AccessKeyID        string  `mapstructure:"access_key_id"`
-	AccessKeySecret    string  `mapstructure:"access_key_secret"`
+	AccessKeySecret    string  `mapstructure:"access_key_secret" secret:"true"`
 	RegionID           string  `mapstructure:"region_id"`
 	SignName           string  `mapstructure:"sign_name"`
 	HTTPTimeout        int64   `mapstructure:"http_timeout"`
 	RateLimitPerSecond float64 `mapstructure:"rate_limit_per_second"`
 	RateLimitBurst     int     `mapstructure:"rate_limit_burst"`
 }
+
+// Format 格式化输出时隐藏敏感字段
+func (cfg AliyunSMSConfig) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, cfg)
+}
@@ -22,7 +22,7 @@ func (cfg *WeixinConfig) Validate() error {
 
 // WorkwxWebHookConfig 企业微信WebHook配置
 type WorkwxWebHookConfig struct {
-	Key       string `mapstructure:"key"`
+	Key       string `mapstructure:"key" secret:"true"`
 	Subscribe string `mapstructure:"subject"`
 }
 
@@ -37,10 +37,10 @@ func (cfg *WorkwxWebHookConfig) Validate() error {
 // WorkwxAppConfig 企业微信应用配置
 type WorkwxAppConfig struct {
 	Address        string `mapstructure:"address"`
-	CorpSecret     string `mapstructure:"corp_secret"`
+	CorpSecret     string `mapstructure:"corp_secret" secret:"true"`
 	AgentID        int64  `mapstructure:"agent_id"`
-	Token          string `mapstructure:"token"`
-	EncodingAESKey string `mapstructure:"encoding_aes_key"`
+	Token          string `mapstructure:"token" secret:"true"`
+	EncodingAESKey string `mapstructure:"encoding_aes_key" secret:"true"`
 	TxSubscribe    string `mapstructure:"tx_subject"`
 	RxSubscribe    string `mapstructure:"rx_subject"`
 }
@@ -61,3 +61,13 @@ func (cfg *WorkwxAppConfig) Validate() error {
 	}
 	return nil
 }
+
+// Format 格式化输出时隐藏敏感字段
+func (cfg WorkwxWebHookConfig) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, cfg)
+}
+
+// Format 格式化输出时隐藏敏感字段
+func (cfg WorkwxAppConfig) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, cfg)
+}
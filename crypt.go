@@ -0,0 +1,318 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+// encryptedAlgorithm 加密值使用的算法
+// 加密值格式为 ENC[aes256-gcm,<密钥标识>,<base64(nonce+密文)>]，可出现在任意字符串叶子值中
+const encryptedAlgorithm = "aes256-gcm"
+
+// encryptedPattern 匹配完整的加密值
+var encryptedPattern = regexp.MustCompile(`ENC\[` + encryptedAlgorithm + `,([A-Za-z0-9_.-]+),([A-Za-z0-9+/=]+)\]`)
+
+// ErrNoKeyProvider 配置中存在加密值但未配置密钥提供者
+var ErrNoKeyProvider = errors.New("no key provider configured for encrypted config values")
+
+// KeyProvider 加密密钥提供者，可对接本地文件、环境变量或 KMS
+type KeyProvider interface {
+	// CurrentKey 返回加密新值使用的密钥及其标识
+	CurrentKey() (id string, key []byte, err error)
+	// Key 返回指定标识的密钥，用于解密
+	Key(id string) ([]byte, error)
+}
+
+// EncryptionConfig 加密值的密钥来源，均为空时不启用解密
+type EncryptionConfig struct {
+	// KeyFile 密钥文件路径
+	KeyFile string `mapstructure:"key_file"`
+	// KeyEnv 存放密钥的环境变量名
+	KeyEnv string `mapstructure:"key_env"`
+}
+
+// staticKeyProvider 基于固定密钥集合的密钥提供者
+type staticKeyProvider struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeyProvider 使用给定的密钥集合创建密钥提供者，current 为加密新值使用的密钥标识
+// 密钥长度必须为 32 字节
+func NewStaticKeyProvider(current string, keys map[string][]byte) (KeyProvider, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("current key %q not found", current)
+	}
+	for id, key := range keys {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key %q must be 32 bytes, got %d", id, len(key))
+		}
+	}
+	return &staticKeyProvider{current: current, keys: keys}, nil
+}
+
+// CurrentKey 返回当前密钥
+func (p *staticKeyProvider) CurrentKey() (string, []byte, error) {
+	return p.current, p.keys[p.current], nil
+}
+
+// Key 返回指定标识的密钥
+func (p *staticKeyProvider) Key(id string) ([]byte, error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %q not found", id)
+	}
+	return key, nil
+}
+
+// NewFileKeyProvider 从密钥文件创建密钥提供者，格式见 ParseKeys
+func NewFileKeyProvider(path string) (KeyProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	return ParseKeys(string(content))
+}
+
+// NewEnvKeyProvider 从环境变量创建密钥提供者，格式见 ParseKeys
+func NewEnvKeyProvider(name string) (KeyProvider, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("key environment variable %s is not set", name)
+	}
+	return ParseKeys(value)
+}
+
+// ParseKeys 解析密钥列表创建密钥提供者
+// 每项为 <标识>:<base64 密钥>，以换行或逗号分隔，# 开头的行为注释，最后一项为当前密钥
+// 轮换密钥时追加新密钥并保留旧密钥，直到 ReencryptSecrets 完成
+func ParseKeys(s string) (KeyProvider, error) {
+	keys := make(map[string][]byte)
+	current := ""
+	for _, entry := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == ',' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid key entry, expected <id>:<base64 key>")
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 for key %q: %w", id, err)
+		}
+		id = strings.TrimSpace(id)
+		keys[id] = key
+		current = id
+	}
+	if current == "" {
+		return nil, fmt.Errorf("no encryption keys found")
+	}
+	return NewStaticKeyProvider(current, keys)
+}
+
+// newKeyProvider 按配置创建密钥提供者，未配置时返回 nil
+func newKeyProvider(cfg EncryptionConfig) (KeyProvider, error) {
+	switch {
+	case cfg.KeyFile != "":
+		return NewFileKeyProvider(cfg.KeyFile)
+	case cfg.KeyEnv != "":
+		return NewEnvKeyProvider(cfg.KeyEnv)
+	default:
+		return nil, nil
+	}
+}
+
+// IsEncrypted 判断值是否为加密值
+func IsEncrypted(value string) bool {
+	return encryptedPattern.MatchString(value)
+}
+
+// EncryptValue 使用当前密钥加密值，返回可直接写入配置组的 ENC[...] 文本
+func EncryptValue(p KeyProvider, plaintext string) (string, error) {
+	id, key, err := p.CurrentKey()
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), encryptedAAD(id))
+	return fmt.Sprintf("ENC[%s,%s,%s]", encryptedAlgorithm, id, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// DecryptValue 解密值中的所有 ENC[...] 片段，不含加密片段的值原样返回
+func DecryptValue(p KeyProvider, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if p == nil {
+		return "", ErrNoKeyProvider
+	}
+
+	var firstErr error
+	out := encryptedPattern.ReplaceAllStringFunc(value, func(token string) string {
+		plaintext, err := decryptToken(p, token)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return plaintext
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return out, nil
+}
+
+// decryptToken 解密单个 ENC[...] 片段
+func decryptToken(p KeyProvider, token string) (string, error) {
+	m := encryptedPattern.FindStringSubmatch(token)
+	id, encoded := m[1], m[2]
+
+	key, err := p.Key(id)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted value with key %q", id)
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], encryptedAAD(id))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value with key %q: %w", id, err)
+	}
+	return string(plaintext), nil
+}
+
+// newGCM 创建 AES-256-GCM 加密器
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptedAAD 将算法与密钥标识绑定到密文
+func encryptedAAD(id string) []byte {
+	return []byte(encryptedAlgorithm + "," + id)
+}
+
+// SetKeyProvider 设置解密配置组中加密值使用的密钥提供者，需在获取配置组之前调用
+func (m *ConfigManager) SetKeyProvider(p KeyProvider) {
+	m.keys = p
+}
+
+// EncryptValue 使用管理器的当前密钥加密值，用于在 Put 之前加密敏感字段
+func (m *ConfigManager) EncryptValue(plaintext string) (string, error) {
+	if m.keys == nil {
+		return "", ErrNoKeyProvider
+	}
+	return EncryptValue(m.keys, plaintext)
+}
+
+// reencryptRoot 返回 ReencryptSecrets 查询的键前缀
+// 前缀总以 "/" 结尾，避免 myapp 同时匹配 myapp2 下的键
+func reencryptRoot(base, prefix string) string {
+	root := base + "/"
+	if p := strings.Trim(prefix, "/"); p != "" {
+		root += p + "/"
+	}
+	return root
+}
+
+// ReencryptSecrets 使用当前密钥重新加密 prefix（相对于 EtcdConfig.Prefix）下所有配置组内容中的加密值
+// 仅改写使用旧密钥加密的值，并以修改版本号做并发保护；审计历史中的旧密文保持不变
+// 返回改写的键数量
+func (m *ConfigManager) ReencryptSecrets(prefix string) (int, error) {
+	if err := m.requireClient(); err != nil {
+		return 0, err
+	}
+	if m.keys == nil {
+		return 0, ErrNoKeyProvider
+	}
+	currentID, _, err := m.keys.CurrentKey()
+	if err != nil {
+		return 0, err
+	}
+
+	root := reencryptRoot(m.cfg.Prefix, prefix)
+	ctx, cancel := m.requestContext()
+	defer cancel()
+	resp, err := m.client.Get(ctx, root, clientv3.WithPrefix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to list config under %s: %w", root, err)
+	}
+
+	rewritten := 0
+	for _, kv := range resp.Kvs {
+		key := string(kv.Key)
+		if !isContentKey(key) || strings.HasPrefix(key, m.cfg.Prefix+"/_history/") {
+			continue
+		}
+
+		var rotateErr error
+		content := encryptedPattern.ReplaceAllStringFunc(string(kv.Value), func(token string) string {
+			if rotateErr != nil || encryptedPattern.FindStringSubmatch(token)[1] == currentID {
+				return token
+			}
+			plaintext, err := decryptToken(m.keys, token)
+			if err == nil {
+				token, err = EncryptValue(m.keys, plaintext)
+			}
+			rotateErr = err
+			return token
+		})
+		if rotateErr != nil {
+			return rewritten, fmt.Errorf("failed to re-encrypt %s: %w", key, rotateErr)
+		}
+		if content == string(kv.Value) {
+			continue
+		}
+
+		txnCtx, txnCancel := m.requestContext()
+		txn, err := m.client.Txn(txnCtx).
+			If(clientv3.Compare(clientv3.ModRevision(key), "=", kv.ModRevision)).
+			Then(clientv3.OpPut(key, content)).
+			Commit()
+		txnCancel()
+		if err != nil {
+			return rewritten, fmt.Errorf("failed to write %s: %w", key, err)
+		}
+		if !txn.Succeeded {
+			return rewritten, fmt.Errorf("%w: key %s changed during re-encryption", ErrRevisionConflict, key)
+		}
+		rewritten++
+		m.logger.Info("配置加密值已使用新密钥重新加密", zap.String("key", key), zap.String("key_id", currentID))
+	}
+	return rewritten, nil
+}
+
+// isContentKey 判断键是否为配置组内容键（content.* 或拆分布局的 content/ 子键）
+func isContentKey(key string) bool {
+	return strings.Contains(key, "/"+contentName+".") || strings.Contains(key, "/"+contentName+"/")
+}
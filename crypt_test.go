@@ -0,0 +1,223 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestEncryptDecryptValue(t *testing.T) {
+	p, err := NewStaticKeyProvider("k1", map[string][]byte{"k1": testKey(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		plaintext string
+	}{
+		{"simple", "s3cret"},
+		{"empty", ""},
+		{"unicode", "密码:p@ss/word"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := EncryptValue(p, tt.plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !IsEncrypted(enc) || !strings.HasPrefix(enc, "ENC[aes256-gcm,k1,") {
+				t.Fatalf("unexpected encrypted value %q", enc)
+			}
+			got, err := DecryptValue(p, "prefix-"+enc+"-suffix")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "prefix-" + tt.plaintext + "-suffix"; got != want {
+				t.Fatalf("DecryptValue = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDecryptValueErrors(t *testing.T) {
+	p, _ := NewStaticKeyProvider("k1", map[string][]byte{"k1": testKey(1)})
+	other, _ := NewStaticKeyProvider("k1", map[string][]byte{"k1": testKey(2)})
+	// k2 与 k1 的密钥相同，密钥标识绑定在附加数据中，改写标识后仍应解密失败
+	aliased, _ := NewStaticKeyProvider("k1", map[string][]byte{"k1": testKey(1), "k2": testKey(1)})
+	enc, err := EncryptValue(p, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := encryptedPattern.FindStringSubmatch(enc)
+	sealed, _ := base64.StdEncoding.DecodeString(m[2])
+	sealed[len(sealed)-1] ^= 0xff
+	tampered := "ENC[aes256-gcm,k1," + base64.StdEncoding.EncodeToString(sealed) + "]"
+
+	tests := []struct {
+		name     string
+		provider KeyProvider
+		value    string
+		wantErr  error
+	}{
+		{"no provider", nil, enc, ErrNoKeyProvider},
+		{"unknown key id", p, strings.Replace(enc, ",k1,", ",k9,", 1), nil},
+		{"renamed key id", aliased, strings.Replace(enc, ",k1,", ",k2,", 1), nil},
+		{"wrong key", other, enc, nil},
+		{"tampered ciphertext", p, tampered, nil},
+		{"truncated ciphertext", p, "ENC[aes256-gcm,k1,AAAA]", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecryptValue(tt.provider, tt.value)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecryptValuePlain(t *testing.T) {
+	got, err := DecryptValue(nil, "plain value")
+	if err != nil || got != "plain value" {
+		t.Fatalf("DecryptValue = %q, %v", got, err)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(testKey(1))
+	k2 := base64.StdEncoding.EncodeToString(testKey(2))
+
+	tests := []struct {
+		name        string
+		input       string
+		wantCurrent string
+		wantErr     bool
+	}{
+		{"single", "k1:" + k1, "k1", false},
+		{"last is current", "k1:" + k1 + "\nk2:" + k2, "k2", false},
+		{"comma separated", "k1:" + k1 + ", k2:" + k2, "k2", false},
+		{"comments and blanks", "# old\nk1:" + k1 + "\n\n# new\nk2:" + k2 + "\n", "k2", false},
+		{"empty", "# nothing\n", "", true},
+		{"missing separator", k1, "", true},
+		{"invalid base64", "k1:not-base64!", "", true},
+		{"short key", "k1:" + base64.StdEncoding.EncodeToString([]byte("short")), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseKeys(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			id, _, err := p.CurrentKey()
+			if err != nil || id != tt.wantCurrent {
+				t.Fatalf("CurrentKey = %q, %v, want %q", id, err, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	old, _ := NewStaticKeyProvider("k1", map[string][]byte{"k1": testKey(1)})
+	rotated, _ := NewStaticKeyProvider("k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+
+	enc, err := EncryptValue(old, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DecryptValue(rotated, enc); err != nil || got != "s3cret" {
+		t.Fatalf("DecryptValue with rotated keys = %q, %v", got, err)
+	}
+	reenc, err := EncryptValue(rotated, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(reenc, ",k2,") {
+		t.Fatalf("expected current key k2, got %q", reenc)
+	}
+	if _, err := DecryptValue(old, reenc); err == nil {
+		t.Fatal("expected old provider to reject value encrypted with k2")
+	}
+}
+
+func TestNewConfigManagerEncryptionKeys(t *testing.T) {
+	static, err := NewStaticKeyProvider("k1", map[string][]byte{"k1": testKey(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		encryption EncryptionConfig
+		keys       KeyProvider
+		wantErr    bool
+		wantKeys   bool
+	}{
+		{name: "not configured"},
+		{name: "missing key file", encryption: EncryptionConfig{KeyFile: "testdata/missing.keys"}, wantErr: true},
+		{name: "unset key env", encryption: EncryptionConfig{KeyEnv: "KMYH_TEST_UNSET_KEYS"}, wantErr: true},
+		{name: "injected provider wins", encryption: EncryptionConfig{KeyFile: "testdata/missing.keys"}, keys: static, wantKeys: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewConfigManager(inParams{
+				AppConfig: &AppConfig{
+					Backend:    BackendConfig{Type: BackendFile, Directory: t.TempDir()},
+					Encryption: tt.encryption,
+				},
+				Logger: zap.NewNop(),
+				Keys:   tt.keys,
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (m.keys != nil) != tt.wantKeys {
+				t.Fatalf("keys = %v, want set %v", m.keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestReencryptRoot(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"", "/configs/"},
+		{"/", "/configs/"},
+		{"myapp", "/configs/myapp/"},
+		{"/myapp/prod/", "/configs/myapp/prod/"},
+	}
+	for _, tt := range tests {
+		if root := reencryptRoot("/configs", tt.prefix); root != tt.want {
+			t.Fatalf("reencryptRoot(%q) = %q, want %q", tt.prefix, root, tt.want)
+		}
+	}
+	if strings.HasPrefix("/configs/myapp2/prod/database/content.yaml", reencryptRoot("/configs", "myapp")) {
+		t.Fatal("myapp root matches keys of myapp2")
+	}
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// MissingKeysError 缺少带有 required:"true" 标签的配置项
+type MissingKeysError struct {
+	// Paths 缺少的点分路径，列表元素以 [i] 表示
+	Paths []string
+}
+
+// Error 列出所有缺少的配置项
+func (e *MissingKeysError) Error() string {
+	return "missing required config keys: " + strings.Join(e.Paths, ", ")
+}
+
+// decodeWithDefaults 按目标类型的 default 标签补全缺少的键后反序列化，并检查 required 标签
+// default 的值按字符串写入后由解码器转换，如 "30s"、"8080"、"a,b"
+// 缺少必填项时仍完成反序列化，并返回列出全部缺少路径的 MissingKeysError
+func decodeWithDefaults(v *viper.Viper, obj interface{}) error {
+	t := reflect.TypeOf(obj)
+	if !hasFieldTags(t, make(map[reflect.Type]bool)) {
+		return v.Unmarshal(obj, decoderOptions)
+	}
+
+	settings := v.AllSettings()
+	var missing []string
+	applyDefaults(t, settings, "", &missing)
+
+	filled := viper.New()
+	if err := filled.MergeConfigMap(settings); err != nil {
+		return err
+	}
+	if err := filled.Unmarshal(obj, decoderOptions); err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return &MissingKeysError{Paths: missing}
+	}
+	return nil
+}
+
+// applyDefaults 为 node 中缺少的键写入默认值，并记录缺少的必填项
+func applyDefaults(t reflect.Type, node map[string]interface{}, prefix string, missing *[]string) {
+	t = derefType(t)
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// 未导出的嵌入结构体的导出字段仍会被解码
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, opts := parseTag(field)
+		if name == "-" {
+			continue
+		}
+		ft := derefType(field.Type)
+		if opts.squash {
+			applyDefaults(ft, node, prefix, missing)
+			continue
+		}
+
+		key := strings.ToLower(name)
+		path := joinKey(prefix, key)
+		raw, present := node[key]
+		if !present || raw == nil {
+			if def, ok := field.Tag.Lookup("default"); ok {
+				node[key] = def
+				continue
+			}
+			// 缺少的嵌套结构体仍可能包含默认值或必填项，指针表示可选的配置段
+			if isStructType(ft) && field.Type.Kind() != reflect.Ptr {
+				child := make(map[string]interface{})
+				applyDefaults(ft, child, path, missing)
+				if len(child) > 0 {
+					node[key] = child
+					continue
+				}
+			}
+			if field.Tag.Get("required") == "true" {
+				*missing = append(*missing, path)
+			}
+			continue
+		}
+
+		switch {
+		case isStructType(ft):
+			if m, ok := raw.(map[string]interface{}); ok {
+				applyDefaults(ft, m, path, missing)
+			}
+		case ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array:
+			if items, ok := raw.([]interface{}); ok && isStructType(derefType(ft.Elem())) {
+				node[key] = applyDefaultsToItems(derefType(ft.Elem()), items, path, missing)
+			}
+		case ft.Kind() == reflect.Map:
+			if m, ok := raw.(map[string]interface{}); ok && isStructType(derefType(ft.Elem())) {
+				for k, item := range m {
+					if im, ok := item.(map[string]interface{}); ok {
+						applyDefaults(ft.Elem(), im, joinKey(path, k), missing)
+					}
+				}
+			}
+		}
+	}
+}
+
+// applyDefaultsToItems 为列表中的每个结构体元素补全默认值
+// 列表与 viper 内部共享，因此在副本上修改
+func applyDefaultsToItems(elem reflect.Type, items []interface{}, path string, missing *[]string) []interface{} {
+	out := make([]interface{}, len(items))
+	for i, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			out[i] = item
+			continue
+		}
+		cp := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			cp[strings.ToLower(k)] = v
+		}
+		applyDefaults(elem, cp, fmt.Sprintf("%s[%d]", path, i), missing)
+		out[i] = cp
+	}
+	return out
+}
+
+// hasFieldTags 判断类型中是否存在 default 或 required 标签
+func hasFieldTags(t reflect.Type, seen map[reflect.Type]bool) bool {
+	t = derefType(t)
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return hasFieldTags(t.Elem(), seen)
+	case reflect.Struct:
+	default:
+		return false
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, ok := field.Tag.Lookup("default"); ok || field.Tag.Get("required") == "true" {
+			return true
+		}
+		if (field.IsExported() || field.Anonymous) && hasFieldTags(field.Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStructType 判断是否为可按键展开的结构体类型
+func isStructType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{})
+}
+
+// derefType 去除指针
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type testJob struct {
+	Name string `mapstructure:"name" required:"true"`
+	Spec string `mapstructure:"spec" default:"@daily"`
+}
+
+type testPool struct {
+	Max int `mapstructure:"max" default:"10"`
+}
+
+type testTLS struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	MinVersion string `mapstructure:"min_version" default:"1.2"`
+}
+
+type testBase struct {
+	Region string `mapstructure:"region" default:"cn"`
+}
+
+type testDefaultsConfig struct {
+	testBase `mapstructure:",squash"`
+	Host     string             `mapstructure:"host" required:"true"`
+	Port     int                `mapstructure:"port" default:"8080"`
+	Timeout  time.Duration      `mapstructure:"timeout" default:"3s"`
+	Tags     []string           `mapstructure:"tags" default:"a,b"`
+	Pool     testPool           `mapstructure:"pool"`
+	TLS      *testTLS           `mapstructure:"tls"`
+	Jobs     []testJob          `mapstructure:"jobs"`
+	Queues   map[string]testJob `mapstructure:"queues"`
+}
+
+func TestDecodeWithDefaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    map[string]interface{}
+		wantMissing []string
+		check       func(t *testing.T, c testDefaultsConfig)
+	}{
+		{
+			name:     "defaults fill missing keys",
+			settings: map[string]interface{}{"host": "h"},
+			check: func(t *testing.T, c testDefaultsConfig) {
+				want := testDefaultsConfig{
+					testBase: testBase{Region: "cn"},
+					Host:     "h",
+					Port:     8080,
+					Timeout:  3 * time.Second,
+					Tags:     []string{"a", "b"},
+					Pool:     testPool{Max: 10},
+				}
+				if !reflect.DeepEqual(c, want) {
+					t.Fatalf("got %+v, want %+v", c, want)
+				}
+			},
+		},
+		{
+			name:     "explicit values win",
+			settings: map[string]interface{}{"host": "h", "port": 9000, "region": "us", "pool": map[string]interface{}{"max": 2}},
+			check: func(t *testing.T, c testDefaultsConfig) {
+				if c.Port != 9000 || c.Region != "us" || c.Pool.Max != 2 {
+					t.Fatalf("got %+v", c)
+				}
+			},
+		},
+		{
+			name:     "optional pointer section stays nil",
+			settings: map[string]interface{}{"host": "h"},
+			check: func(t *testing.T, c testDefaultsConfig) {
+				if c.TLS != nil {
+					t.Fatalf("TLS = %+v, want nil", c.TLS)
+				}
+			},
+		},
+		{
+			name:     "present pointer section gets defaults",
+			settings: map[string]interface{}{"host": "h", "tls": map[string]interface{}{"enabled": true}},
+			check: func(t *testing.T, c testDefaultsConfig) {
+				if c.TLS == nil || !c.TLS.Enabled || c.TLS.MinVersion != "1.2" {
+					t.Fatalf("TLS = %+v", c.TLS)
+				}
+			},
+		},
+		{
+			name: "slice and map elements",
+			settings: map[string]interface{}{
+				"host":   "h",
+				"jobs":   []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"spec": "* * *"}},
+				"queues": map[string]interface{}{"q1": map[string]interface{}{"name": "x"}, "q2": map[string]interface{}{"spec": "x"}},
+			},
+			wantMissing: []string{"jobs[1].name", "queues.q2.name"},
+			check: func(t *testing.T, c testDefaultsConfig) {
+				want := []testJob{{Name: "a", Spec: "@daily"}, {Spec: "* * *"}}
+				if !reflect.DeepEqual(c.Jobs, want) {
+					t.Fatalf("Jobs = %+v, want %+v", c.Jobs, want)
+				}
+				if c.Queues["q1"].Spec != "@daily" {
+					t.Fatalf("Queues = %+v", c.Queues)
+				}
+			},
+		},
+		{
+			name:        "missing required",
+			settings:    map[string]interface{}{},
+			wantMissing: []string{"host"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := viper.New()
+			if err := v.MergeConfigMap(tt.settings); err != nil {
+				t.Fatal(err)
+			}
+
+			var c testDefaultsConfig
+			err := decodeWithDefaults(v, &c)
+			var missingErr *MissingKeysError
+			switch {
+			case len(tt.wantMissing) == 0 && err != nil:
+				t.Fatal(err)
+			case len(tt.wantMissing) > 0 && !errors.As(err, &missingErr):
+				t.Fatalf("error = %v, want MissingKeysError", err)
+			case len(tt.wantMissing) > 0:
+				got := append([]string(nil), missingErr.Paths...)
+				if !sameStrings(got, tt.wantMissing) {
+					t.Fatalf("missing = %v, want %v", got, tt.wantMissing)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, c)
+			}
+		})
+	}
+}
+
+func TestDecodeWithDefaultsDoesNotModifySource(t *testing.T) {
+	v := viper.New()
+	_ = v.MergeConfigMap(map[string]interface{}{
+		"host": "h",
+		"jobs": []interface{}{map[string]interface{}{"name": "a"}},
+	})
+
+	var c testDefaultsConfig
+	if err := decodeWithDefaults(v, &c); err != nil {
+		t.Fatal(err)
+	}
+	if v.IsSet("port") {
+		t.Fatal("defaults written to source config")
+	}
+	if job := v.Get("jobs").([]interface{})[0].(map[string]interface{}); len(job) != 1 {
+		t.Fatalf("list item modified: %v", job)
+	}
+}
+
+func TestDecodeWithoutTags(t *testing.T) {
+	v := viper.New()
+	_ = v.MergeConfigMap(map[string]interface{}{"host": "h"})
+
+	var c struct {
+		Host string `mapstructure:"host"`
+		Port int    `mapstructure:"port"`
+	}
+	if err := decodeWithDefaults(v, &c); err != nil || c.Host != "h" || c.Port != 0 {
+		t.Fatalf("got %+v, %v", c, err)
+	}
+}
+
+// sameStrings 判断两个字符串列表是否包含相同的元素（不考虑顺序）
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := make(map[string]int, len(a))
+	for _, s := range a {
+		count[s]++
+	}
+	for _, s := range b {
+		count[s]--
+	}
+	for _, n := range count {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
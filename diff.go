@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeSet 两份配置之间按点分路径计算的差异
+type ChangeSet struct {
+	Added    []string `json:"added,omitempty"`
+	Removed  []string `json:"removed,omitempty"`
+	Modified []string `json:"modified,omitempty"`
+}
+
+// Empty 判断是否没有任何差异
+func (c ChangeSet) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// diffSettings 计算两份配置树之间的差异
+func diffSettings(old, new map[string]interface{}) ChangeSet {
+	oldFlat := flattenSettings(old)
+	newFlat := flattenSettings(new)
+
+	var cs ChangeSet
+	for path, nv := range newFlat {
+		ov, exists := oldFlat[path]
+		if !exists {
+			cs.Added = append(cs.Added, path)
+		} else if !reflect.DeepEqual(ov, nv) {
+			cs.Modified = append(cs.Modified, path)
+		}
+	}
+	for path := range oldFlat {
+		if _, exists := newFlat[path]; !exists {
+			cs.Removed = append(cs.Removed, path)
+		}
+	}
+
+	sort.Strings(cs.Added)
+	sort.Strings(cs.Removed)
+	sort.Strings(cs.Modified)
+	return cs
+}
+
+// flattenSettings 将配置树展开为点分路径到叶子值的映射
+// 键名统一转为小写，与 viper 的键名规则保持一致
+func flattenSettings(settings map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	flattenInto(out, "", settings)
+	return out
+}
+
+func flattenInto(out map[string]interface{}, prefix string, value interface{}) {
+	var children map[string]interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		children = v
+	case map[interface{}]interface{}:
+		children = make(map[string]interface{}, len(v))
+		for k, cv := range v {
+			children[toString(k)] = cv
+		}
+	default:
+		if prefix != "" {
+			out[prefix] = value
+		}
+		return
+	}
+
+	// 空的子树视为一个叶子，避免新增或删除空节点时丢失差异
+	if len(children) == 0 && prefix != "" {
+		out[prefix] = children
+		return
+	}
+	for k, cv := range children {
+		path := strings.ToLower(k)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		flattenInto(out, path, cv)
+	}
+}
+
+func toString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
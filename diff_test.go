@@ -0,0 +1,69 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		old  map[string]interface{}
+		new  map[string]interface{}
+		want ChangeSet
+	}{
+		{
+			name: "identical",
+			old:  map[string]interface{}{"host": "a", "db": map[string]interface{}{"port": 1}},
+			new:  map[string]interface{}{"host": "a", "db": map[string]interface{}{"port": 1}},
+			want: ChangeSet{},
+		},
+		{
+			name: "added removed modified",
+			old:  map[string]interface{}{"host": "a", "port": 1},
+			new:  map[string]interface{}{"host": "b", "user": "u"},
+			want: ChangeSet{Added: []string{"user"}, Removed: []string{"port"}, Modified: []string{"host"}},
+		},
+		{
+			name: "nested paths",
+			old:  map[string]interface{}{"db": map[string]interface{}{"host": "a", "pool": map[string]interface{}{"max": 1}}},
+			new:  map[string]interface{}{"db": map[string]interface{}{"host": "a", "pool": map[string]interface{}{"max": 2, "min": 0}}},
+			want: ChangeSet{Added: []string{"db.pool.min"}, Modified: []string{"db.pool.max"}},
+		},
+		{
+			name: "list is a leaf",
+			old:  map[string]interface{}{"tags": []interface{}{"a"}},
+			new:  map[string]interface{}{"tags": []interface{}{"a", "b"}},
+			want: ChangeSet{Modified: []string{"tags"}},
+		},
+		{
+			name: "empty subtree",
+			old:  map[string]interface{}{},
+			new:  map[string]interface{}{"db": map[string]interface{}{}},
+			want: ChangeSet{Added: []string{"db"}},
+		},
+		{
+			name: "keys are lowercased",
+			old:  map[string]interface{}{"Host": "a"},
+			new:  map[string]interface{}{"host": "a"},
+			want: ChangeSet{},
+		},
+		{
+			name: "subtree replaced by scalar",
+			old:  map[string]interface{}{"db": map[string]interface{}{"host": "a"}},
+			new:  map[string]interface{}{"db": "dsn"},
+			want: ChangeSet{Added: []string{"db"}, Removed: []string{"db.host"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffSettings(tt.old, tt.new)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("diffSettings = %+v, want %+v", got, tt.want)
+			}
+			if got.Empty() != tt.want.Empty() {
+				t.Fatalf("Empty = %v", got.Empty())
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix 环境变量前缀
+//
+// 主配置 AppConfig 的取值优先级（高到低）：
+//  1. 命令行参数，例如 --etcd-endpoints=a:2379,b:2379
+//  2. 环境变量，例如 KMYH_ETCD_ENDPOINTS=a:2379,b:2379、KMYH_ENV=prod
+//  3. 配置文件 config.yaml
+//
+// 配置组开启 AppConfig.EnvOverlay 后的取值优先级（高到低）：
+//  1. 环境变量，例如 KMYH_DATABASE_HOST 覆盖 database 组的 host
+//  2. 实例层、环境层、默认层（见 LayerConfig）
+//
+// 环境变量可覆盖配置组中已存在的键，以及通过 GetConfig 获取时目标结构体声明的键（etcd 中缺失也生效）
+// 覆盖在解析引用之前进行，其值不会写入本地快照
+const EnvPrefix = "KMYH"
+
+// envKeyReplacer 将点分键名转换为环境变量名
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// configKey 结构体字段对应的点分键名及其类型
+type configKey struct {
+	path string
+	typ  reflect.Type
+}
+
+// structKeys 按 mapstructure 标签列出结构体的所有叶子键
+func structKeys(t reflect.Type, prefix string) []configKey {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var keys []configKey
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts := parseTag(field)
+		if name == "-" {
+			continue
+		}
+
+		path := prefix
+		if !opts.squash {
+			path = joinKey(prefix, strings.ToLower(name))
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+			keys = append(keys, structKeys(ft, path)...)
+			continue
+		}
+		keys = append(keys, configKey{path: path, typ: field.Type})
+	}
+	return keys
+}
+
+// bindEnv 将结构体的所有键绑定到带前缀的环境变量
+func bindEnv(v *viper.Viper, t reflect.Type) {
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(envKeyReplacer)
+	v.AutomaticEnv()
+	for _, k := range structKeys(t, "") {
+		_ = v.BindEnv(k.path)
+	}
+}
+
+// RegisterFlags 为 AppConfig 的每个键注册命令行参数
+// 参数名由键名转换而来，例如 etcd.dial_timeout 对应 --etcd-dial-timeout
+func RegisterFlags(fs *pflag.FlagSet) {
+	for _, k := range structKeys(reflect.TypeOf(AppConfig{}), "") {
+		name := flagName(k.path)
+		if fs.Lookup(name) != nil {
+			continue
+		}
+		usage := "overrides " + k.path + " in config file"
+		switch {
+		case k.typ == reflect.TypeOf(time.Duration(0)):
+			fs.Duration(name, 0, usage)
+		case k.typ.Kind() == reflect.Bool:
+			fs.Bool(name, false, usage)
+		case k.typ.Kind() == reflect.Int:
+			fs.Int(name, 0, usage)
+		case k.typ.Kind() == reflect.Slice && k.typ.Elem().Kind() == reflect.String:
+			fs.StringSlice(name, nil, usage)
+		default:
+			fs.String(name, "", usage)
+		}
+	}
+}
+
+// bindFlags 将已注册的命令行参数绑定到对应的键，仅显式设置的参数会覆盖配置
+func bindFlags(v *viper.Viper, fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+	for _, k := range structKeys(reflect.TypeOf(AppConfig{}), "") {
+		if f := fs.Lookup(flagName(k.path)); f != nil && f.Changed {
+			_ = v.BindPFlag(k.path, f)
+		}
+	}
+}
+
+// overlayEnv 使用环境变量原地覆盖配置树中已存在的键与 keys 中的键，返回是否有值被覆盖
+// 例如 KMYH_DATABASE_HOST 覆盖 database 组的 host
+func overlayEnv(settings map[string]interface{}, group string, keys []string) bool {
+	prefix := EnvPrefix + "_" + envName(group) + "_"
+	paths := flattenSettings(settings)
+	for _, k := range keys {
+		paths[k] = nil
+	}
+
+	overlaid := false
+	for path := range paths {
+		if value, ok := os.LookupEnv(prefix + envName(path)); ok {
+			setPath(settings, strings.Split(path, "."), value)
+			overlaid = true
+		}
+	}
+	return overlaid
+}
+
+// overlay 开启 EnvOverlay 时在配置树之上应用环境变量覆盖，返回是否有值被覆盖
+func (m *ConfigManager) overlay(settings map[string]interface{}, group string, keys []string) bool {
+	return m.envOverlay && overlayEnv(settings, group, keys)
+}
+
+// bindGroupEnv 将目标结构体的键加入配置组的环境变量覆盖范围
+// 新加入的键存在对应的环境变量时重新解析配置组，使 etcd 中缺失的键也能由环境变量提供
+func (m *ConfigManager) bindGroupEnv(group ConfigGroup, t reflect.Type) {
+	g, ok := group.(*configGroup)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !ok || !m.envOverlay || t.Kind() != reflect.Struct {
+		return
+	}
+
+	prefix := EnvPrefix + "_" + envName(g.name) + "_"
+	found := false
+	g.mu.Lock()
+	if g.envKeys == nil {
+		g.envKeys = make(map[string]bool)
+	}
+	for _, k := range structKeys(t, "") {
+		if g.envKeys[k.path] {
+			continue
+		}
+		g.envKeys[k.path] = true
+		if _, ok := os.LookupEnv(prefix + envName(k.path)); ok {
+			found = true
+		}
+	}
+	g.mu.Unlock()
+
+	if found {
+		m.reapply(g)
+	}
+}
+
+// boundEnvKeys 返回配置组已绑定的结构体键
+func (g *configGroup) boundEnvKeys() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	keys := make([]string, 0, len(g.envKeys))
+	for k := range g.envKeys {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func envName(key string) string {
+	return strings.ToUpper(envKeyReplacer.Replace(key))
+}
+
+func flagName(key string) string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(key)
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -1,46 +1,66 @@
 package config
 
 import (
-	"crypto/tls"
-	"google.golang.org/grpc"
+	"context"
+	"fmt"
 	"time"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+
 	"go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 )
 
 // NewEtcdClient 根据提供的配置创建etcd客户端
 // 该函数会配置连接参数、认证信息和TLS设置
-// 返回创建的客户端实例和可能的错误
+// 客户端以非阻塞方式创建，连接在后台建立，连通性由 CheckEtcdConnectivity 检查
 func NewEtcdClient(cfg EtcdConfig, logger *zap.Logger) (*clientv3.Client, error) {
-	log := logger.With(zap.Namespace("[etcd client]")).Sugar()
+	log := subLogger(logger, "[etcd client]").Sugar()
+
+	autoSync := durationOrDefault(cfg.AutoSyncInterval, defaultAutoSyncInterval)
+	if autoSync < 0 {
+		autoSync = 0
+	}
+
+	backoffConfig := backoff.DefaultConfig
+	backoffConfig.MaxDelay = durationOrDefault(cfg.BackoffMaxDelay, defaultBackoffMaxDelay)
+
 	etcConf := clientv3.Config{
 		Endpoints:   cfg.Endpoints,
 		DialTimeout: cfg.DialTimeout,
 		Username:    cfg.Username,
 		Password:    cfg.Password,
-		// 关键配置 2：使用 grpc.WithBlock() 确保连接建立
+		// 连接重试间隔
 		DialOptions: []grpc.DialOption{
-			grpc.WithBlock(),
-			grpc.WithBackoffMaxDelay(3 * time.Second), // 重试间隔
+			grpc.WithConnectParams(grpc.ConnectParams{
+				Backoff:           backoffConfig,
+				MinConnectTimeout: cfg.DialTimeout,
+			}),
 		},
 
-		// 关键配置 3：启用自动重连
-		AutoSyncInterval: 30 * time.Second, // 自动同步端点列表
+		// 自动同步端点列表
+		AutoSyncInterval: autoSync,
+
+		// KeepAlive 参数
+		DialKeepAliveTime:    durationOrDefault(cfg.KeepAliveTime, defaultKeepAliveTime),
+		DialKeepAliveTimeout: durationOrDefault(cfg.KeepAliveTimeout, defaultKeepAliveTimeout),
 
-		// 关键配置 4：KeepAlive 参数
-		DialKeepAliveTime:    5 * time.Second,
-		DialKeepAliveTimeout: 5 * time.Second,
+		MaxCallSendMsgSize: cfg.MaxCallSendMsgSize,
+		MaxCallRecvMsgSize: cfg.MaxCallRecvMsgSize,
+		RejectOldCluster:   cfg.RejectOldCluster,
 	}
 
 	if cfg.TLS != nil {
-		tlsConfig, err := tls.LoadX509KeyPair(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		tlsConfig, err := cfg.TLS.ClientConfig()
 		if err != nil {
+			log.Error("加载 TLS 配置失败", zap.Error(err))
 			return nil, err
 		}
-		etcConf.TLS = &tls.Config{
-			Certificates: []tls.Certificate{tlsConfig},
+		if cfg.TLS.InsecureSkipVerify {
+			log.Warn("已跳过 etcd 服务端证书校验，仅应在测试环境使用")
 		}
+		etcConf.TLS = tlsConfig
 	}
 
 	client, err := clientv3.New(etcConf)
@@ -49,7 +69,43 @@ func NewEtcdClient(cfg EtcdConfig, logger *zap.Logger) (*clientv3.Client, error)
 		return nil, err
 	}
 
-	log.Info("etcd 客户端连接成功",
+	log.Info("etcd 客户端已创建",
 		zap.Strings("endpoints", cfg.Endpoints))
 	return client, nil
 }
+
+// CheckEtcdConnectivity 检查 etcd 集群是否可用，等待至 ctx 结束或 DialTimeout 到期
+func CheckEtcdConnectivity(ctx context.Context, client *clientv3.Client, cfg EtcdConfig) error {
+	timeout := cfg.DialTimeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// 线性读取要求集群存在 leader
+	if _, err := client.Get(ctx, cfg.Prefix, clientv3.WithCountOnly()); err != nil {
+		return fmt.Errorf("etcd cluster %v unavailable: %w", cfg.Endpoints, err)
+	}
+	return nil
+}
+
+// startEtcdClient 在 fx 启动阶段检查 etcd 连通性
+// 启用 StartDegraded 时连接失败只记录警告，配置组从快照启动并在连接恢复后同步
+func startEtcdClient(ctx context.Context, client *clientv3.Client, cfg EtcdConfig, logger *zap.Logger) error {
+	log := subLogger(logger, "[etcd client]").Sugar()
+
+	start := time.Now()
+	if err := CheckEtcdConnectivity(ctx, client, cfg); err != nil {
+		if !cfg.StartDegraded {
+			log.Error("etcd 连接失败", zap.Error(err))
+			return err
+		}
+		log.Warn("etcd 连接失败，以降级模式启动", zap.Error(err))
+		return nil
+	}
+
+	log.Info("etcd 客户端连接成功",
+		zap.Strings("endpoints", cfg.Endpoints), zap.Duration("elapsed", time.Since(start)))
+	return nil
+}
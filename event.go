@@ -0,0 +1,52 @@
+package config
+
+import "strings"
+
+// ChangeEvent 配置组变更事件
+type ChangeEvent struct {
+	// Group 配置组在 etcd 中的键
+	Group string
+	// OldRevision 变更前的修改版本号
+	OldRevision int64
+	// NewRevision 变更后的修改版本号
+	NewRevision int64
+	// ChangeSet 按点分路径计算的差异
+	ChangeSet
+}
+
+// Affects 判断事件是否涉及指定路径前缀
+func (e ChangeEvent) Affects(prefix string) bool {
+	return !e.filter(prefix).Empty()
+}
+
+// filter 仅保留指定路径前缀下的差异
+func (e ChangeEvent) filter(prefix string) ChangeEvent {
+	prefix = strings.ToLower(strings.TrimSuffix(prefix, "."))
+	if prefix == "" {
+		return e
+	}
+	out := e
+	out.ChangeSet = ChangeSet{
+		Added:    filterPaths(e.Added, prefix),
+		Removed:  filterPaths(e.Removed, prefix),
+		Modified: filterPaths(e.Modified, prefix),
+	}
+	return out
+}
+
+// filterPaths 过滤出等于前缀或位于前缀之下的路径
+func filterPaths(paths []string, prefix string) []string {
+	var out []string
+	for _, p := range paths {
+		if p == prefix || strings.HasPrefix(p, prefix+".") {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// eventSubscriber 按路径前缀订阅的变更回调
+type eventSubscriber struct {
+	prefix string
+	fn     func(ChangeEvent)
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChangeEventFilter(t *testing.T) {
+	event := ChangeEvent{
+		Group:       "/configs/app/prod/database",
+		OldRevision: 1,
+		NewRevision: 2,
+		ChangeSet: ChangeSet{
+			Added:    []string{"db.pool.min", "dbx"},
+			Removed:  []string{"cache.ttl"},
+			Modified: []string{"db", "db.host"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   ChangeSet
+	}{
+		{"empty prefix keeps all", "", event.ChangeSet},
+		{"exact and nested", "db", ChangeSet{Added: []string{"db.pool.min"}, Modified: []string{"db", "db.host"}}},
+		{"trailing dot", "db.", ChangeSet{Added: []string{"db.pool.min"}, Modified: []string{"db", "db.host"}}},
+		{"case insensitive", "DB.Pool", ChangeSet{Added: []string{"db.pool.min"}}},
+		{"no partial segment match", "db.po", ChangeSet{}},
+		{"unrelated", "redis", ChangeSet{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := event.filter(tt.prefix)
+			if !reflect.DeepEqual(got.ChangeSet, tt.want) {
+				t.Fatalf("filter(%q) = %+v, want %+v", tt.prefix, got.ChangeSet, tt.want)
+			}
+			if got.Group != event.Group || got.OldRevision != 1 || got.NewRevision != 2 {
+				t.Fatalf("filter dropped event metadata: %+v", got)
+			}
+			if event.Affects(tt.prefix) == tt.want.Empty() {
+				t.Fatalf("Affects(%q) = %v", tt.prefix, event.Affects(tt.prefix))
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
@@ -39,6 +40,11 @@ func main() {
 	}
 	defer etcdClient.Close()
 
+	// 客户端以非阻塞方式创建，按需检查连通性
+	if err := config.CheckEtcdConnectivity(context.Background(), etcdClient, appConfig.Etcd); err != nil {
+		log.Printf("etcd unavailable, continuing with cached config: %v", err)
+	}
+
 	// 创建 ConfigManager
 	configManager := config.NewConfigManagerDirect(etcdClient, logger, appConfig)
 
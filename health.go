@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WatchState 配置组的监听状态
+type WatchState int
+
+const (
+	// WatchStateWatching 正常监听中
+	WatchStateWatching WatchState = iota
+	// WatchStateReconnecting 监听中断，正在重连
+	WatchStateReconnecting
+	// WatchStateStale 配置来自本地快照或空配置，尚未与后端同步
+	WatchStateStale
+	// WatchStateStopped 监听已停止
+	WatchStateStopped
+)
+
+// String 返回监听状态名称
+func (s WatchState) String() string {
+	switch s {
+	case WatchStateWatching:
+		return "watching"
+	case WatchStateReconnecting:
+		return "reconnecting"
+	case WatchStateStale:
+		return "stale"
+	case WatchStateStopped:
+		return "stopped"
+	default:
+		return fmt.Sprintf("WatchState(%d)", int(s))
+	}
+}
+
+// WatchState 返回配置组的监听状态，取各配置层中最差的状态
+func (g *configGroup) WatchState() WatchState {
+	if g.Stale() {
+		return WatchStateStale
+	}
+
+	g.layerMu.Lock()
+	defer g.layerMu.Unlock()
+	state := WatchStateWatching
+	for _, l := range g.layers {
+		state = max(state, l.state)
+	}
+	return state
+}
+
+// setLayerState 更新单个配置层的监听状态
+func (g *configGroup) setLayerState(l *layer, state WatchState) {
+	g.layerMu.Lock()
+	defer g.layerMu.Unlock()
+	l.state = state
+}
+
+// WatchStates 返回所有配置组的监听状态，用于健康检查
+func (m *ConfigManager) WatchStates() map[string]WatchState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	states := make(map[string]WatchState, len(m.groups))
+	for key, g := range m.groups {
+		states[key] = g.WatchState()
+	}
+	return states
+}
+
+// Healthy 所有配置组均处于正常监听状态时返回 nil，否则返回异常配置组列表
+func (m *ConfigManager) Healthy() error {
+	var unhealthy []string
+	for key, state := range m.WatchStates() {
+		if state != WatchStateWatching {
+			unhealthy = append(unhealthy, key+"="+state.String())
+		}
+	}
+	if len(unhealthy) == 0 {
+		return nil
+	}
+	sort.Strings(unhealthy)
+	return fmt.Errorf("config groups not watching: %s", strings.Join(unhealthy, ", "))
+}
@@ -0,0 +1,312 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+// defaultHistoryLimit 每个配置组默认保留的审计历史条数
+const defaultHistoryLimit = 50
+
+// ErrRevisionNotFound 指定版本既不在 etcd 历史中，也没有审计记录
+var ErrRevisionNotFound = errors.New("config revision not found")
+
+// HistoryEntry 配置组的一个历史版本
+type HistoryEntry struct {
+	// Revision 该版本在 etcd 中的修改版本号
+	Revision int64 `json:"revision"`
+	// Author 写入者，仅在存在审计记录时可用
+	Author string `json:"author,omitempty"`
+	// Comment 写入说明，仅在存在审计记录时可用
+	Comment string `json:"comment,omitempty"`
+	// Time 写入时间，仅在存在审计记录时可用
+	Time time.Time `json:"time,omitempty"`
+	// Content 该版本的完整配置内容
+	Content string `json:"content"`
+	// Changes 相对上一个版本的差异，最早的版本为空
+	Changes ChangeSet `json:"changes"`
+	// ParseError 该版本的内容无法按配置组格式解析时的错误，此时与相邻版本之间不计算差异
+	ParseError string `json:"parse_error,omitempty"`
+	// Compacted 该版本已被 etcd 压缩，内容来自审计记录
+	Compacted bool `json:"compacted,omitempty"`
+}
+
+// auditRecord 与配置内容在同一事务中写入的审计记录
+type auditRecord struct {
+	Author  string    `json:"author"`
+	Comment string    `json:"comment,omitempty"`
+	Time    time.Time `json:"time"`
+	Content string    `json:"content"`
+}
+
+// History 列出配置组的历史版本，按版本号从新到旧排列
+// 优先使用 etcd 的 MVCC 历史，被压缩的版本由审计记录补齐
+// limit 小于等于 0 时使用 EtcdConfig.HistoryLimit
+func (m *ConfigManager) History(app, env, group string, limit int) ([]HistoryEntry, error) {
+	layout, err := m.contentLayout(app, env, group)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = m.historyLimit()
+	}
+
+	entries, err := m.auditEntries(app, env, group)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.mvccEntries(layout.key, limit, entries); err != nil {
+		return nil, err
+	}
+
+	list := make([]HistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, *e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Revision > list[j].Revision
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+
+	parsed := make([]map[string]interface{}, len(list))
+	for i := range list {
+		settings, err := parseContent(list[i].Content, layout.format)
+		if err != nil {
+			m.logger.Warn("解析历史版本内容失败", zap.Int64("revision", list[i].Revision), zap.Error(err))
+			list[i].ParseError = err.Error()
+			continue
+		}
+		parsed[i] = settings
+	}
+	for i := 0; i+1 < len(list); i++ {
+		if parsed[i] != nil && parsed[i+1] != nil {
+			list[i].Changes = diffSettings(parsed[i+1], parsed[i])
+		}
+	}
+	return list, nil
+}
+
+// Rollback 将配置组恢复到指定版本
+// 恢复以一次新的写入完成，并以当前版本号做比较交换，避免覆盖并发修改
+func (m *ConfigManager) Rollback(app, env, group string, revision int64, opts ...PutOption) (int64, error) {
+	layout, err := m.contentLayout(app, env, group)
+	if err != nil {
+		return 0, err
+	}
+
+	content, err := m.contentAt(app, env, group, layout.key, revision)
+	if err != nil {
+		return 0, err
+	}
+
+	current, err := m.Revision(app, env, group)
+	if err != nil {
+		return 0, err
+	}
+
+	opts = append([]PutOption{
+		WithExpectedRevision(current),
+		WithComment(fmt.Sprintf("rollback to revision %d", revision)),
+	}, opts...)
+
+	rev, err := m.put(app, env, group, layout.key, []byte(content), opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	m.logger.Info("配置已回滚",
+		zap.String("key", layout.key),
+		zap.Int64("from", current),
+		zap.Int64("to", revision),
+		zap.Int64("revision", rev))
+	return rev, nil
+}
+
+// contentAt 获取配置组在指定版本的内容
+func (m *ConfigManager) contentAt(app, env, group, key string, revision int64) (string, error) {
+	ctx, cancel := m.requestContext()
+	defer cancel()
+
+	resp, err := m.client.Get(ctx, key, clientv3.WithRev(revision))
+	switch {
+	case err == nil:
+		if len(resp.Kvs) > 0 && resp.Kvs[0].ModRevision == revision {
+			return string(resp.Kvs[0].Value), nil
+		}
+	case errors.Is(err, rpctypes.ErrCompacted):
+		// 已压缩，回退到审计记录
+	default:
+		return "", fmt.Errorf("failed to get config %s at revision %d: %w", key, revision, err)
+	}
+
+	entries, err := m.auditEntries(app, env, group)
+	if err != nil {
+		return "", err
+	}
+	if e, ok := entries[revision]; ok {
+		return e.Content, nil
+	}
+	return "", fmt.Errorf("%w: key %s revision %d", ErrRevisionNotFound, key, revision)
+}
+
+// mvccEntries 沿 etcd 的 MVCC 历史向前遍历配置组的版本
+func (m *ConfigManager) mvccEntries(key string, limit int, entries map[int64]*HistoryEntry) error {
+	ctx, cancel := m.requestContext()
+	defer cancel()
+
+	var opts []clientv3.OpOption
+	for n := 0; n < limit; n++ {
+		resp, err := m.client.Get(ctx, key, opts...)
+		if errors.Is(err, rpctypes.ErrCompacted) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get config history %s: %w", key, err)
+		}
+		if len(resp.Kvs) == 0 {
+			return nil
+		}
+
+		kv := resp.Kvs[0]
+		e, exists := entries[kv.ModRevision]
+		if !exists {
+			e = &HistoryEntry{Revision: kv.ModRevision}
+			entries[kv.ModRevision] = e
+		}
+		e.Content = string(kv.Value)
+		e.Compacted = false
+
+		// Version 为 1 表示该键的创建版本
+		if kv.Version <= 1 {
+			return nil
+		}
+		opts = []clientv3.OpOption{clientv3.WithRev(kv.ModRevision - 1)}
+	}
+	return nil
+}
+
+// auditEntries 读取配置组的审计记录，按版本号索引
+func (m *ConfigManager) auditEntries(app, env, group string) (map[int64]*HistoryEntry, error) {
+	prefix := m.historyKey(app, env, group) + "/"
+	ctx, cancel := m.requestContext()
+	defer cancel()
+
+	resp, err := m.client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config audit %s: %w", prefix, err)
+	}
+
+	entries := make(map[int64]*HistoryEntry, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var rec auditRecord
+		if err := json.Unmarshal(kv.Value, &rec); err != nil {
+			m.logger.Warn("解析审计记录失败", zap.String("key", string(kv.Key)), zap.Error(err))
+			continue
+		}
+		// 审计记录与配置内容在同一事务中写入，修改版本号一致
+		entries[kv.ModRevision] = &HistoryEntry{
+			Revision:  kv.ModRevision,
+			Author:    rec.Author,
+			Comment:   rec.Comment,
+			Time:      rec.Time,
+			Content:   rec.Content,
+			Compacted: true,
+		}
+	}
+	return entries, nil
+}
+
+// pruneHistory 删除超出保留条数的旧审计记录
+func (m *ConfigManager) pruneHistory(app, env, group string) {
+	prefix := m.historyKey(app, env, group) + "/"
+	ctx, cancel := m.requestContext()
+	defer cancel()
+
+	resp, err := m.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly(),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		m.logger.Warn("读取审计记录失败", zap.String("prefix", prefix), zap.Error(err))
+		return
+	}
+
+	excess := len(resp.Kvs) - m.historyLimit()
+	if excess <= 0 {
+		return
+	}
+
+	// 审计键按时间递增，删除 [最早, 第 excess 条) 区间
+	first, end := string(resp.Kvs[0].Key), string(resp.Kvs[excess].Key)
+	if _, err := m.client.Delete(ctx, first, clientv3.WithRange(end)); err != nil {
+		m.logger.Warn("清理审计记录失败", zap.String("prefix", prefix), zap.Error(err))
+	}
+}
+
+// historyKey 返回配置组审计记录在 etcd 中的前缀
+func (m *ConfigManager) historyKey(app, env, group string) string {
+	return fmt.Sprintf("%s/_history/%s/%s/%s", m.cfg.Prefix, app, env, group)
+}
+
+func (m *ConfigManager) historyLimit() int {
+	if m.cfg.HistoryLimit > 0 {
+		return m.cfg.HistoryLimit
+	}
+	return defaultHistoryLimit
+}
+
+// newAuditRecord 生成本次写入的审计记录
+func newAuditRecord(o putOptions, content []byte) ([]byte, error) {
+	rec, err := json.Marshal(auditRecord{
+		Author:  o.author,
+		Comment: o.comment,
+		Time:    time.Now(),
+		Content: string(content),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	return rec, nil
+}
+
+// auditID 生成按时间排序的审计记录键名
+func auditID() string {
+	return fmt.Sprintf("%020d", time.Now().UnixNano())
+}
+
+// defaultAuthor 未指定作者时使用 "用户@主机名"
+func defaultAuthor() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return name
+}
+
+// parseContent 按配置组的内容格式解析配置内容，空内容视为空配置
+func parseContent(content, format string) (map[string]interface{}, error) {
+	if strings.TrimSpace(content) == "" {
+		return make(map[string]interface{}), nil
+	}
+	v := viper.New()
+	v.SetConfigType(format)
+	if err := v.ReadConfig(strings.NewReader(content)); err != nil {
+		return nil, fmt.Errorf("failed to parse %s content: %w", format, err)
+	}
+	return v.AllSettings(), nil
+}
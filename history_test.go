@@ -0,0 +1,40 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		format  string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{"yaml", "host: a\n", "yaml", map[string]interface{}{"host": "a"}, false},
+		{"json", `{"host": "a"}`, "json", map[string]interface{}{"host": "a"}, false},
+		{"toml", "host = \"a\"\n", "toml", map[string]interface{}{"host": "a"}, false},
+		{"empty", "\n", "json", map[string]interface{}{}, false},
+		{"toml parsed as toml", "[db]\nhost = \"a\"\n", "toml", map[string]interface{}{"db": map[string]interface{}{"host": "a"}}, false},
+		{"invalid", "host = ", "toml", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseContent(tt.content, tt.format)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseContent = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
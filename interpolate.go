@@ -0,0 +1,234 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// resolver 一次解析过程中的变量求值上下文
+type resolver struct {
+	m     *ConfigManager
+	app   string
+	env   string
+	group string
+	// settings 按配置组名称缓存的原始配置（点分路径），本配置组为正在解析的候选配置
+	settings map[string]map[string]interface{}
+	files    map[string]bool
+	groups   map[string]bool
+}
+
+// newResolver 创建解析上下文，own 为正在解析的配置组的原始配置
+func newResolver(m *ConfigManager, g *configGroup, own map[string]interface{}) *resolver {
+	return &resolver{
+		m:        m,
+		app:      g.app,
+		env:      g.env,
+		group:    g.name,
+		settings: map[string]map[string]interface{}{g.name: own},
+		files:    make(map[string]bool),
+		groups:   make(map[string]bool),
+	}
+}
+
+// refs 返回解析过程中引用的文件与其他配置组
+func (r *resolver) refs() refSet {
+	var refs refSet
+	for f := range r.files {
+		refs.files = append(refs.files, f)
+	}
+	for g := range r.groups {
+		refs.groups = append(refs.groups, g)
+	}
+	sort.Strings(refs.files)
+	sort.Strings(refs.groups)
+	return refs
+}
+
+// resolve 解密值并替换其中的引用与变量
+// stack 为正在求值的变量链（group.key），用于检测循环引用
+func (r *resolver) resolve(group, path, value string, stack []string) (string, error) {
+	value, err := DecryptValue(r.m.keys, value)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+
+	var firstErr error
+	var replace func(ref string) string
+	replace = func(ref string) string {
+		if firstErr != nil {
+			return ref
+		}
+		if strings.HasPrefix(ref, "$$") {
+			if !r.m.interpolate {
+				// 转义随变量插值一同开启，未开启时 "$" 保持原样，其后的引用照常处理
+				return "$" + replace(ref[1:])
+			}
+			return ref[1:]
+		}
+		expr := ref[2 : len(ref)-1]
+		if _, _, isSource := sourceRef(expr); !isSource && !r.m.interpolate {
+			// 未开启变量插值时仅解析文件与环境变量引用，其余内容保持原样
+			return ref
+		}
+		resolved, err := r.expand(group, expr, stack)
+		if err != nil {
+			// 嵌套求值的错误只在最外层标注引用，避免重复
+			firstErr = err
+			if len(stack) == 0 {
+				firstErr = fmt.Errorf("cannot resolve %s: %w", ref, err)
+			}
+			return ref
+		}
+		return resolved
+	}
+	out := refPattern.ReplaceAllStringFunc(value, replace)
+	return out, firstErr
+}
+
+// expand 求值单个引用表达式
+func (r *resolver) expand(group, expr string, stack []string) (string, error) {
+	if kind, target, ok := sourceRef(expr); ok {
+		return resolveSource(kind, target, r.files)
+	}
+
+	name, def, hasDefault := strings.Cut(expr, ":-")
+	name = strings.TrimSpace(name)
+	value, found, err := r.lookup(group, name, stack)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		if hasDefault {
+			return def, nil
+		}
+		return "", fmt.Errorf("variable %q is not defined", name)
+	}
+	return value, nil
+}
+
+// sourceRef 判断表达式是否为 file: 或 env: 引用
+// ${env:-prod} 与 ${file:-x} 为带默认值的变量，不视为引用
+func sourceRef(expr string) (kind, target string, ok bool) {
+	kind, target, ok = strings.Cut(expr, ":")
+	if !ok || strings.HasPrefix(target, "-") || (kind != "file" && kind != "env") {
+		return "", "", false
+	}
+	return kind, target, true
+}
+
+// lookup 查找变量的值
+// app、env 为配置组所属的应用与环境；不含点号的名称为本配置组的键，否则首段为配置组名称
+func (r *resolver) lookup(group, name string, stack []string) (string, bool, error) {
+	switch name {
+	case "":
+		return "", false, fmt.Errorf("empty variable name")
+	case "app":
+		return r.app, true, nil
+	case "env":
+		return r.env, true, nil
+	}
+
+	target, path := group, strings.ToLower(name)
+	if g, p, ok := strings.Cut(path, "."); ok {
+		target, path = g, p
+	}
+
+	id := target + "." + path
+	for i, s := range stack {
+		if s == id {
+			return "", false, fmt.Errorf("reference cycle: %s", strings.Join(append(stack[i:], id), " -> "))
+		}
+	}
+
+	settings := r.groupSettings(target)
+	raw, ok := settings[path]
+	if !ok || raw == nil {
+		return "", false, nil
+	}
+	if _, isTree := raw.(map[string]interface{}); isTree {
+		return "", false, fmt.Errorf("variable %q refers to a config subtree", name)
+	}
+	s, ok := raw.(string)
+	if !ok {
+		return fmt.Sprint(raw), true, nil
+	}
+	value, err := r.resolve(target, path, s, append(stack, id))
+	return value, err == nil, err
+}
+
+// groupSettings 返回配置组的原始配置，其他配置组在首次引用时读取
+func (r *resolver) groupSettings(name string) map[string]interface{} {
+	if settings, ok := r.settings[name]; ok {
+		return settings
+	}
+	r.groups[name] = true
+	settings := flattenSettings(r.m.rawSettings(r.app, r.env, name))
+	r.settings[name] = settings
+	return settings
+}
+
+// rawSettings 返回配置组应用环境变量覆盖后、解析前的配置
+// 已注册的配置组直接使用其当前配置，否则从后端读取且不注册，读取失败时视为空配置
+func (m *ConfigManager) rawSettings(app, env, group string) map[string]interface{} {
+	m.mu.RLock()
+	existing, exists := m.groups[m.backend.Key(app, env, group)]
+	m.mu.RUnlock()
+	if exists {
+		g := existing.(*configGroup)
+		keys := g.boundEnvKeys()
+		g.mu.RLock()
+		raw := g.raw
+		if raw == nil {
+			raw = g.viper
+		}
+		settings := raw.AllSettings()
+		g.mu.RUnlock()
+		m.overlay(settings, group, keys)
+		return settings
+	}
+
+	tmp := &configGroup{
+		logger:     m.logger,
+		name:       group,
+		layerState: layerState{layers: m.newLayers(app, env, group)},
+	}
+	v, _, err := m.loadLayers(tmp)
+	if err != nil {
+		m.logger.Debug("读取被引用的配置组失败", zap.String("group", group), zap.Error(err))
+		return nil
+	}
+	settings := v.AllSettings()
+	m.overlay(settings, group, nil)
+	return settings
+}
+
+// trackGroupRefs 订阅被引用配置组的变更，变更后重新解析引用方
+// 被引用的配置组会被注册到管理器，每个配置组只订阅一次
+func (m *ConfigManager) trackGroupRefs(g *configGroup, groups []string) {
+	for _, name := range groups {
+		g.mu.Lock()
+		subscribed := g.depends[name]
+		if !subscribed {
+			if g.depends == nil {
+				g.depends = make(map[string]bool)
+			}
+			g.depends[name] = true
+		}
+		g.mu.Unlock()
+		if subscribed {
+			continue
+		}
+
+		dep := m.GetGroup(g.app, g.env, name)
+		dep.OnChange(func() {
+			g.logger.Info("被引用的配置组已变更，重新解析配置", zap.String("dependency", name))
+			m.reapply(g)
+		})
+	}
+}
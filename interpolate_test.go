@@ -0,0 +1,136 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestResolver(interpolate bool, own map[string]interface{}, others map[string]map[string]interface{}) *resolver {
+	m := &ConfigManager{interpolate: interpolate}
+	g := &configGroup{name: "app", app: "shop", env: "prod"}
+	r := newResolver(m, g, flattenSettings(own))
+	for name, settings := range others {
+		r.settings[name] = flattenSettings(settings)
+	}
+	return r
+}
+
+func TestResolverInterpolation(t *testing.T) {
+	t.Setenv("KMYH_TEST_REGION", "cn")
+	own := map[string]interface{}{
+		"name":    "${app}-${env}",
+		"url":     "http://${host}:${port}",
+		"host":    "localhost",
+		"port":    8080,
+		"path":    "/${name}",
+		"chain":   "${path}/v1",
+		"literal": "$${host}",
+	}
+	others := map[string]map[string]interface{}{
+		"database": {"host": "db.${env}", "port": 5432},
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"builtins", "${app}/${env}", "shop/prod"},
+		{"own key", "${host}", "localhost"},
+		{"non string value", "${port}", "8080"},
+		{"transitive", "${url}", "http://localhost:8080"},
+		{"nested variables", "${chain}", "/shop-prod/v1"},
+		{"other group", "${database.host}:${database.port}", "db.prod:5432"},
+		{"default for missing", "${missing:-fallback}", "fallback"},
+		{"default unused", "${host:-fallback}", "localhost"},
+		{"empty default", "[${missing:-}]", "[]"},
+		{"env named variable with default", "${env:-dev}", "prod"},
+		{"env source", "${env:KMYH_TEST_REGION}", "cn"},
+		{"escape", "$${app}", "${app}"},
+		{"escape in referenced value", "${literal}", "${host}"},
+		{"no references", "plain", "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestResolver(true, own, others)
+			got, err := r.resolve("app", "test", tt.value, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("resolve(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolverErrors(t *testing.T) {
+	own := map[string]interface{}{
+		"a":    "${b}",
+		"b":    "${c}",
+		"c":    "${a}",
+		"self": "x${self}",
+		"x":    "${database.y}",
+		"tree": map[string]interface{}{},
+	}
+	others := map[string]map[string]interface{}{
+		"database": {"y": "${app.x}"},
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{"three step cycle", "${a}", "reference cycle: app.a -> app.b -> app.c -> app.a"},
+		{"self reference", "${self}", "reference cycle: app.self -> app.self"},
+		{"cross group cycle", "${x}", "reference cycle: app.x -> database.y -> app.x"},
+		{"undefined", "${missing}", `variable "missing" is not defined`},
+		{"subtree", "${tree}", "refers to a config subtree"},
+		{"empty name", "${}", "empty variable name"},
+		{"unset env source", "${env:KMYH_TEST_UNSET_VARIABLE}", "is not set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestResolver(true, own, others)
+			_, err := r.resolve("app", "test", tt.value, nil)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			// 嵌套求值的错误只在最外层标注一次引用
+			if n := strings.Count(err.Error(), "cannot resolve"); n != 1 {
+				t.Fatalf("error wraps %d times: %v", n, err)
+			}
+		})
+	}
+}
+
+func TestResolverInterpolationDisabled(t *testing.T) {
+	t.Setenv("KMYH_TEST_REGION", "cn")
+	r := newTestResolver(false, map[string]interface{}{"host": "localhost"}, nil)
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"${host}", "${host}"},
+		{"${app}-${missing}", "${app}-${missing}"},
+		{"${env:-dev}", "${env:-dev}"},
+		{"${env:KMYH_TEST_REGION}/${host}", "cn/${host}"},
+		{"pa$${x}ss", "pa$${x}ss"},
+		{"$${host}", "$${host}"},
+		{"$${env:KMYH_TEST_REGION}", "$cn"},
+	}
+	for _, tt := range tests {
+		got, err := r.resolve("app", "test", tt.value, nil)
+		if err != nil {
+			t.Fatalf("resolve(%q): %v", tt.value, err)
+		}
+		if got != tt.want {
+			t.Fatalf("resolve(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// defaultLayerEnv 默认层使用的环境名
+const defaultLayerEnv = "_default"
+
+// LayerConfig 分层配置
+// 启用后配置组按 默认层 -> 环境层 -> 实例层 的顺序深度合并，后者覆盖前者
+type LayerConfig struct {
+	// Enabled 是否启用分层配置
+	Enabled bool `mapstructure:"enabled"`
+	// DefaultEnv 默认层的环境名，默认为 _default
+	DefaultEnv string `mapstructure:"default_env"`
+	// Instance 实例名，非空时在环境层之上叠加 <env>/_instances/<instance> 层
+	Instance string `mapstructure:"instance"`
+}
+
+// layer 配置组的一个配置层
+type layer struct {
+	key      string
+	settings map[string]interface{}
+	revision int64
+	// readRevision 最近一次读取该层时的后端版本号，作为监听起点
+	readRevision int64
+	state        WatchState
+}
+
+// layerState 配置组分层状态，保护各层内容
+type layerState struct {
+	layers  []*layer
+	layerMu sync.Mutex
+	// applyMu 串行化 合并 -> 解析 -> 校验 -> 替换 的完整流程，避免各层监听、重新同步与重新解析引用并发时旧的结果后生效
+	// 加锁顺序：applyMu -> layerMu -> configGroup.mu
+	applyMu sync.Mutex
+}
+
+// newLayers 按合并顺序返回配置组的各层，未启用分层时仅包含环境层
+func (m *ConfigManager) newLayers(app, env, group string) []*layer {
+	if !m.layers.Enabled {
+		return []*layer{{key: m.backend.Key(app, env, group)}}
+	}
+
+	defaultEnv := m.layers.DefaultEnv
+	if defaultEnv == "" {
+		defaultEnv = defaultLayerEnv
+	}
+	envs := []string{defaultEnv, env}
+	if m.layers.Instance != "" {
+		envs = append(envs, env+"/_instances/"+m.layers.Instance)
+	}
+
+	layers := make([]*layer, 0, len(envs))
+	for _, e := range envs {
+		layers = append(layers, &layer{key: m.backend.Key(app, e, group)})
+	}
+	return layers
+}
+
+// loadLayers 读取配置组的所有层并合并
+// 仅当所有层都读取失败时返回错误，缺失的层视为空配置
+func (m *ConfigManager) loadLayers(g *configGroup) (*viper.Viper, int64, error) {
+	g.layerMu.Lock()
+	defer g.layerMu.Unlock()
+
+	var errs []string
+	for _, l := range g.layers {
+		v, revision, readRevision, err := m.backend.Load(l.key)
+		if err != nil {
+			if len(g.layers) > 1 {
+				g.logger.Debug("读取配置层失败", zap.String("layer", l.key), zap.Error(err))
+			}
+			errs = append(errs, err.Error())
+			continue
+		}
+		l.settings = v.AllSettings()
+		l.revision = revision
+		l.readRevision = readRevision
+	}
+	if len(errs) == len(g.layers) {
+		return nil, 0, fmt.Errorf("failed to load config layers: %s", strings.Join(errs, "; "))
+	}
+
+	v, revision := mergeLayers(g.layers)
+	return v, revision, nil
+}
+
+// watchGroup 监听配置组每一层的变更
+func (m *ConfigManager) watchGroup(g *configGroup) {
+	for _, l := range g.layers {
+		g.layerMu.Lock()
+		opts := WatchOptions{
+			Revision: l.readRevision,
+			Apply: func(candidate *viper.Viper, revision int64) {
+				m.applyLayer(g, l, candidate, revision)
+			},
+			State: func(state WatchState, err error) {
+				g.setLayerState(l, state)
+				if errors.Is(err, ErrContentDeleted) {
+					g.notifyErrors(fmt.Errorf("config %s rejected: %w", l.key, err))
+				}
+			},
+			SecretPatterns: m.secretPatterns,
+			SecretPaths:    g.boundSecretPaths,
+		}
+		g.layerMu.Unlock()
+
+		g.spawn(func() {
+			m.backend.Watch(g.ctx, l.key, opts)
+		})
+	}
+}
+
+// applyLayer 更新单个配置层后重新合并并应用
+func (m *ConfigManager) applyLayer(g *configGroup, l *layer, candidate *viper.Viper, revision int64) {
+	g.applyMu.Lock()
+	defer g.applyMu.Unlock()
+
+	g.layerMu.Lock()
+	l.settings = candidate.AllSettings()
+	l.revision = revision
+	merged, mergedRevision := mergeLayers(g.layers)
+	g.layerMu.Unlock()
+
+	if len(g.layers) > 1 {
+		g.logger.Info("配置层变更", zap.String("layer", l.key), zap.Int64("revision", revision))
+	}
+	m.applyCandidate(g, merged, mergedRevision)
+}
+
+// mergeLayers 按顺序深度合并各层，返回合并结果和各层中最大的修改版本号
+func mergeLayers(layers []*layer) (*viper.Viper, int64) {
+	merged := make(map[string]interface{})
+	var revision int64
+	for _, l := range layers {
+		mergeSettings(merged, l.settings)
+		revision = max(revision, l.revision)
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	_ = v.MergeConfigMap(merged)
+	return v, revision
+}
+
+// mergeSettings 将 src 深度合并到 dst
+// 合并规则：map 递归合并；标量与列表整体覆盖；显式的 null 删除该键
+func mergeSettings(dst, src map[string]interface{}) {
+	for k, sv := range src {
+		if sv == nil {
+			delete(dst, k)
+			continue
+		}
+		srcMap, srcIsMap := sv.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		switch {
+		case srcIsMap && dstIsMap:
+			mergeSettings(dstMap, srcMap)
+		case srcIsMap:
+			copied := make(map[string]interface{}, len(srcMap))
+			mergeSettings(copied, srcMap)
+			dst[k] = copied
+		default:
+			dst[k] = sv
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		dst  map[string]interface{}
+		src  map[string]interface{}
+		want map[string]interface{}
+	}{
+		{
+			name: "scalar override",
+			dst:  map[string]interface{}{"host": "a", "port": 1},
+			src:  map[string]interface{}{"port": 2},
+			want: map[string]interface{}{"host": "a", "port": 2},
+		},
+		{
+			name: "nested maps merge",
+			dst:  map[string]interface{}{"db": map[string]interface{}{"host": "a", "port": 1}},
+			src:  map[string]interface{}{"db": map[string]interface{}{"port": 2, "user": "u"}},
+			want: map[string]interface{}{"db": map[string]interface{}{"host": "a", "port": 2, "user": "u"}},
+		},
+		{
+			name: "lists replace",
+			dst:  map[string]interface{}{"tags": []interface{}{"a", "b"}},
+			src:  map[string]interface{}{"tags": []interface{}{"c"}},
+			want: map[string]interface{}{"tags": []interface{}{"c"}},
+		},
+		{
+			name: "null deletes",
+			dst:  map[string]interface{}{"host": "a", "db": map[string]interface{}{"port": 1, "user": "u"}},
+			src:  map[string]interface{}{"host": nil, "db": map[string]interface{}{"user": nil}},
+			want: map[string]interface{}{"db": map[string]interface{}{"port": 1}},
+		},
+		{
+			name: "map replaces scalar",
+			dst:  map[string]interface{}{"db": "dsn"},
+			src:  map[string]interface{}{"db": map[string]interface{}{"host": "a"}},
+			want: map[string]interface{}{"db": map[string]interface{}{"host": "a"}},
+		},
+		{
+			name: "scalar replaces map",
+			dst:  map[string]interface{}{"db": map[string]interface{}{"host": "a"}},
+			src:  map[string]interface{}{"db": "dsn"},
+			want: map[string]interface{}{"db": "dsn"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mergeSettings(tt.dst, tt.src)
+			if !reflect.DeepEqual(tt.dst, tt.want) {
+				t.Fatalf("mergeSettings = %v, want %v", tt.dst, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeSettingsDoesNotAliasSource(t *testing.T) {
+	src := map[string]interface{}{"db": map[string]interface{}{"host": "a"}}
+	dst := make(map[string]interface{})
+	mergeSettings(dst, src)
+	mergeSettings(dst, map[string]interface{}{"db": map[string]interface{}{"host": "b"}})
+
+	if got := src["db"].(map[string]interface{})["host"]; got != "a" {
+		t.Fatalf("source layer modified: host = %v", got)
+	}
+}
+
+func TestMergeLayers(t *testing.T) {
+	layers := []*layer{
+		{settings: map[string]interface{}{"host": "default", "port": 1}, revision: 5},
+		{settings: map[string]interface{}{"host": "prod"}, revision: 9},
+		{settings: nil, revision: 0},
+	}
+	v, revision := mergeLayers(layers)
+	if revision != 9 {
+		t.Fatalf("revision = %d, want 9", revision)
+	}
+	if v.GetString("host") != "prod" || v.GetInt("port") != 1 {
+		t.Fatalf("merged = %v", v.AllSettings())
+	}
+}
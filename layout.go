@@ -0,0 +1,171 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/viper"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.yaml.in/yaml/v3"
+)
+
+// contentName 配置组内容键名
+//
+// 配置组支持以下存储布局（均位于 <prefix>/<app>/<env>/<group>/ 之下）：
+//   - content.yaml / content.json / content.toml：整份内容存放在单个键中，按此顺序优先
+//   - content/<a>/<b>：拆分布局，每个叶子值存放在独立的键中，读取时组装为 a.b
+const contentName = "content"
+
+// contentFormats 单键布局支持的格式，按优先级排列
+var contentFormats = []string{"yaml", "json", "toml"}
+
+// groupLayout 配置组的存储布局
+type groupLayout struct {
+	// key 单键布局下的内容键
+	key string
+	// format 单键布局下的内容格式
+	format string
+	// exploded 是否为拆分布局
+	exploded bool
+}
+
+// detectLayout 检测配置组的存储布局，配置组不存在时视为 content.yaml
+func detectLayout(ctx context.Context, client *clientv3.Client, base string) (groupLayout, error) {
+	resp, err := client.Get(ctx, base+"/"+contentName, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return groupLayout{}, fmt.Errorf("failed to detect config layout %s: %w", base, err)
+	}
+
+	present := make(map[string]bool, len(resp.Kvs))
+	exploded := false
+	for _, kv := range resp.Kvs {
+		key := string(kv.Key)
+		present[key] = true
+		if strings.HasPrefix(key, explodedPrefix(base)) {
+			exploded = true
+		}
+	}
+
+	for _, format := range contentFormats {
+		if key := contentKey(base, format); present[key] {
+			return groupLayout{key: key, format: format}, nil
+		}
+	}
+	if exploded {
+		return groupLayout{exploded: true}, nil
+	}
+	return groupLayout{key: contentKey(base, "yaml"), format: "yaml"}, nil
+}
+
+// assembleExploded 将拆分布局下的叶子键组装为配置树，忽略不属于拆分布局的键
+func assembleExploded(base string, kvs map[string][]byte) (*viper.Viper, error) {
+	prefix := explodedPrefix(base)
+	settings := make(map[string]interface{})
+	for key, value := range kvs {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		path := strings.Split(strings.Trim(strings.TrimPrefix(key, prefix), "/"), "/")
+		setPath(settings, path, parseLeaf(value))
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, fmt.Errorf("failed to assemble exploded config %s: %w", base, err)
+	}
+	return v, nil
+}
+
+// decodeGroup 按与 detectLayout 相同的优先级，从配置组内容键的当前值解析配置
+// kvs 为 <base>/content 前缀下的键值，内容已全部删除时返回空配置
+func decodeGroup(base string, kvs map[string][]byte) (*viper.Viper, error) {
+	for _, format := range contentFormats {
+		if value, ok := kvs[contentKey(base, format)]; ok {
+			v := viper.New()
+			v.SetConfigType(format)
+			if err := v.ReadConfig(bytes.NewReader(value)); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", contentKey(base, format), err)
+			}
+			return v, nil
+		}
+	}
+	return assembleExploded(base, kvs)
+}
+
+// setPath 按路径写入叶子值，路径上的非 map 节点会被替换
+func setPath(settings map[string]interface{}, path []string, value interface{}) {
+	node := settings
+	for _, p := range path[:len(path)-1] {
+		child, ok := node[p].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			node[p] = child
+		}
+		node = child
+	}
+	node[path[len(path)-1]] = value
+}
+
+// parseLeaf 将叶子键的值按 YAML 标量解析，保留数字与布尔类型
+func parseLeaf(raw []byte) interface{} {
+	var value interface{}
+	if err := yaml.Unmarshal(raw, &value); err != nil || value == nil {
+		return string(raw)
+	}
+	return value
+}
+
+// contentRevision 返回 decodeGroup 所选布局的修改版本号，拆分布局取各叶子键的最大值
+func contentRevision(base string, revisions map[string]int64) int64 {
+	for _, format := range contentFormats {
+		if revision, ok := revisions[contentKey(base, format)]; ok {
+			return revision
+		}
+	}
+
+	var revision int64
+	for key, r := range revisions {
+		if strings.HasPrefix(key, explodedPrefix(base)) {
+			revision = max(revision, r)
+		}
+	}
+	return revision
+}
+
+// contentKey 返回单键布局下指定格式的内容键
+func contentKey(base, format string) string {
+	return base + "/" + contentName + "." + format
+}
+
+// explodedPrefix 返回拆分布局的叶子键前缀
+func explodedPrefix(base string) string {
+	return base + "/" + contentName + "/"
+}
+
+// explodedKey 返回点分路径在拆分布局下对应的键
+func explodedKey(base, path string) string {
+	return explodedPrefix(base) + strings.ReplaceAll(path, ".", "/")
+}
+
+// encodeContent 按格式序列化配置树
+func encodeContent(settings map[string]interface{}, format string) ([]byte, error) {
+	switch format {
+	case "yaml":
+		return yaml.Marshal(settings)
+	case "json":
+		return json.MarshalIndent(settings, "", "  ")
+	case "toml":
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(settings); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported config format: %s", format)
+	}
+}
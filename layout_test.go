@@ -0,0 +1,117 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeGroup(t *testing.T) {
+	const base = "/configs/app/prod/database"
+
+	tests := []struct {
+		name    string
+		kvs     map[string][]byte
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "yaml",
+			kvs:  map[string][]byte{base + "/content.yaml": []byte("host: a\nport: 5432\n")},
+			want: map[string]interface{}{"host": "a", "port": 5432},
+		},
+		{
+			name: "json",
+			kvs:  map[string][]byte{base + "/content.json": []byte(`{"host": "a", "pool": {"max": 3}}`)},
+			want: map[string]interface{}{"host": "a", "pool": map[string]interface{}{"max": float64(3)}},
+		},
+		{
+			name: "toml",
+			kvs:  map[string][]byte{base + "/content.toml": []byte("host = \"a\"\n[pool]\nmax = 3\n")},
+			want: map[string]interface{}{"host": "a", "pool": map[string]interface{}{"max": int64(3)}},
+		},
+		{
+			name: "yaml wins over json and exploded",
+			kvs: map[string][]byte{
+				base + "/content.json":   []byte(`{"host": "json"}`),
+				base + "/content.yaml":   []byte("host: yaml\n"),
+				base + "/content/host":   []byte("exploded"),
+				base + "/content.toml.x": []byte("ignored"),
+			},
+			want: map[string]interface{}{"host": "yaml"},
+		},
+		{
+			name: "exploded",
+			kvs: map[string][]byte{
+				base + "/content/host":     []byte("a"),
+				base + "/content/port":     []byte("5432"),
+				base + "/content/pool/max": []byte("3"),
+				base + "/content/tls/on":   []byte("true"),
+				base + "/content/dsn":      []byte("user:pw@tcp(a)/db"),
+			},
+			want: map[string]interface{}{
+				"host": "a",
+				"port": 5432,
+				"pool": map[string]interface{}{"max": 3},
+				"tls":  map[string]interface{}{"on": true},
+				"dsn":  "user:pw@tcp(a)/db",
+			},
+		},
+		{
+			name: "exploded ignores unrelated keys",
+			kvs: map[string][]byte{
+				base + "/content/host": []byte("a"),
+				base + "/contents":     []byte("other"),
+			},
+			want: map[string]interface{}{"host": "a"},
+		},
+		{
+			name: "empty",
+			kvs:  map[string][]byte{},
+			want: map[string]interface{}{},
+		},
+		{
+			name:    "invalid yaml",
+			kvs:     map[string][]byte{base + "/content.yaml": []byte("host: [a\n")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := decodeGroup(base, tt.kvs)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := v.AllSettings(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("decodeGroup = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentRevision(t *testing.T) {
+	const base = "/configs/app/prod/database"
+
+	tests := []struct {
+		name      string
+		revisions map[string]int64
+		want      int64
+	}{
+		{"single key", map[string]int64{base + "/content.yaml": 7, base + "/content/host": 9}, 7},
+		{"json before toml", map[string]int64{base + "/content.toml": 3, base + "/content.json": 5}, 5},
+		{"exploded max", map[string]int64{base + "/content/a": 4, base + "/content/b/c": 11}, 11},
+		{"empty", map[string]int64{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contentRevision(base, tt.revisions); got != tt.want {
+				t.Fatalf("contentRevision = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// lifecycle 监听协程与回调的生命周期，配置组与配置管理器各持有一个
+type lifecycle struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	lifeMu sync.Mutex
+	closed bool
+}
+
+// spawn 在生命周期内异步执行 fn，关闭后不再启动新的协程，返回 fn 是否已启动
+func (l *lifecycle) spawn(fn func()) bool {
+	l.lifeMu.Lock()
+	if l.closed {
+		l.lifeMu.Unlock()
+		return false
+	}
+	l.wg.Add(1)
+	l.lifeMu.Unlock()
+
+	go func() {
+		defer l.wg.Done()
+		fn()
+	}()
+	return true
+}
+
+// close 取消监听并等待所有协程与回调结束，ctx 到期时提前返回
+func (l *lifecycle) close(ctx context.Context) error {
+	l.lifeMu.Lock()
+	l.closed = true
+	l.lifeMu.Unlock()
+	l.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CloseGroup 停止单个配置组的监听并将其从管理器中移除
+// 等待该组的监听协程与进行中的回调结束，已获取的 ConfigGroup 与 Live 句柄保留最后的值
+func (m *ConfigManager) CloseGroup(ctx context.Context, app, env, group string) error {
+	key := m.backend.Key(app, env, group)
+
+	m.mu.Lock()
+	g, exists := m.groups[key]
+	delete(m.groups, key)
+	m.mu.Unlock()
+
+	if !exists {
+		return nil
+	}
+	m.logger.Info("关闭配置组", zap.String("key", key))
+	// 不再监听该组引用的文件
+	m.trackRefs(g.(*configGroup), nil)
+	return g.(*configGroup).close(ctx)
+}
+
+// Stop 停止所有监听（fx 生命周期）
+// 取消所有配置组的监听，并在 ctx 截止前等待监听协程、进行中的回调与引用文件监听结束
+func (m *ConfigManager) Stop(ctx context.Context) error {
+	m.logger.Info("配置管理器停止")
+	m.cancel()
+
+	m.mu.Lock()
+	groups := make([]*configGroup, 0, len(m.groups))
+	for _, g := range m.groups {
+		groups = append(groups, g.(*configGroup))
+	}
+	m.groups = make(map[string]ConfigGroup)
+	m.mu.Unlock()
+
+	// 超时后仍需关闭其余配置组，close 在 ctx 到期时立即返回，确保 Stop 返回后不再启动新的协程
+	var waitErr error
+	for _, g := range groups {
+		if err := g.close(ctx); err != nil {
+			m.logger.Warn("等待配置组监听结束超时", zap.String("key", g.groupKey), zap.Error(err))
+			if waitErr == nil {
+				waitErr = err
+			}
+		}
+	}
+	if err := m.close(ctx); err != nil {
+		m.logger.Warn("等待引用文件监听结束超时", zap.Error(err))
+		if waitErr == nil {
+			waitErr = err
+		}
+	}
+
+	if m.client != nil {
+		if err := m.client.Close(); err != nil {
+			return err
+		}
+	}
+	return waitErr
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLifecycleSpawnAfterClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &lifecycle{ctx: ctx, cancel: cancel}
+
+	release := make(chan struct{})
+	if !l.spawn(func() { <-release }) {
+		t.Fatal("spawn before close did not start")
+	}
+
+	timeout, cancelTimeout := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelTimeout()
+	if err := l.close(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("close with running goroutine = %v, want deadline exceeded", err)
+	}
+	if l.ctx.Err() == nil {
+		t.Fatal("close did not cancel the context")
+	}
+	if l.spawn(func() { t.Error("spawned after close") }) {
+		t.Fatal("spawn after close reported started")
+	}
+
+	close(release)
+	if err := l.close(context.Background()); err != nil {
+		t.Fatalf("close after goroutines finished = %v", err)
+	}
+}
+
+func TestCloseWaitsForCallbacks(t *testing.T) {
+	tests := []struct {
+		name  string
+		close func(m *ConfigManager, ctx context.Context) error
+	}{
+		{"CloseGroup", func(m *ConfigManager, ctx context.Context) error {
+			return m.CloseGroup(ctx, "app", "prod", "database")
+		}},
+		{"Stop", func(m *ConfigManager, ctx context.Context) error {
+			return m.Stop(ctx)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, dir := newFileManager(t, nil, map[string]string{"app/prod/database.yaml": "host: a\n"})
+			g := m.GetGroup("app", "prod", "database")
+
+			var started, finished atomic.Int32
+			release := make(chan struct{})
+			g.OnChange(func() {
+				if started.Add(1) == 1 {
+					<-release
+				}
+				finished.Add(1)
+			})
+			rewriteUntil(t, filepath.Join(dir, "app/prod/database.yaml"), "host: b\n", func() bool {
+				return started.Load() > 0
+			}, "callback to start")
+
+			done := make(chan error, 1)
+			go func() { done <- tt.close(m, context.Background()) }()
+			select {
+			case err := <-done:
+				t.Fatalf("close returned %v before the callback finished", err)
+			case <-time.After(50 * time.Millisecond):
+			}
+			close(release)
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatal(err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("close did not return after the callback finished")
+			}
+			if finished.Load() != started.Load() {
+				t.Fatalf("close returned with %d of %d callbacks running", started.Load()-finished.Load(), started.Load())
+			}
+
+			// 关闭后的文件变更不再触发回调
+			n := started.Load()
+			writeTestFile(t, filepath.Join(dir, "app/prod/database.yaml"), "host: c\n")
+			time.Sleep(3 * fileDebounce)
+			if started.Load() != n {
+				t.Fatal("callback ran after close")
+			}
+			if got := g.GetString("host"); got != "b" {
+				t.Fatalf("closed group host = %q, want last value b", got)
+			}
+		})
+	}
+}
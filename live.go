@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// Live 强类型的动态配置句柄
+// 配置组每次变更时重新反序列化并原子替换，调用方通过 Load 获取最新值
+type Live[T any] struct {
+	group    ConfigGroup
+	logger   *zap.SugaredLogger
+	value    atomic.Pointer[T]
+	watchers []func(old, new T)
+	mu       sync.RWMutex
+	reloadMu sync.Mutex
+}
+
+// Watch 根据泛型类型获取动态配置句柄
+// 配置组名称规则与 GetConfig 相同
+func Watch[T any](m *ConfigManager, app, env string) (*Live[T], error) {
+	group := groupNameOf[T]()
+	l := &Live[T]{
+		group:  m.GetGroup(app, env, group),
+		logger: m.logger.With(zap.String("live", group)),
+	}
+
+	registerValidator[T](l.group)
+	bindSecretPaths(l.group, reflect.TypeOf((*T)(nil)).Elem())
+
+	var initial T
+	if err := l.group.Unmarshal(&initial); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	if err := validateValue(&initial); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	l.value.Store(&initial)
+
+	l.group.OnChange(l.reload)
+	return l, nil
+}
+
+// Load 获取当前配置值
+func (l *Live[T]) Load() T {
+	return *l.value.Load()
+}
+
+// OnChange 注册强类型的配置变更回调
+// 回调在配置替换完成后执行，参数为变更前后的配置值
+func (l *Live[T]) OnChange(fn func(old, new T)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.watchers = append(l.watchers, fn)
+}
+
+// reload 重新反序列化配置并通知回调
+func (l *Live[T]) reload() {
+	// 串行化重载，避免并发回调导致新旧值乱序
+	l.reloadMu.Lock()
+	defer l.reloadMu.Unlock()
+
+	var next T
+	if err := l.group.Unmarshal(&next); err != nil {
+		l.logger.Error("反序列化配置失败，保留当前值", zap.Error(err))
+		return
+	}
+
+	old := l.value.Swap(&next)
+	if reflect.DeepEqual(*old, next) {
+		return
+	}
+
+	l.mu.RLock()
+	watchers := make([]func(old, new T), len(l.watchers))
+	copy(watchers, l.watchers)
+	l.mu.RUnlock()
+
+	for _, fn := range watchers {
+		fn(*old, next)
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testLiveConfig struct {
+	Host string `mapstructure:"host" required:"true"`
+	Port int    `mapstructure:"port" default:"80"`
+}
+
+// Validate 端口不能为负数
+func (c *testLiveConfig) Validate() error {
+	if c.Port < 0 {
+		return errors.New("port must not be negative")
+	}
+	return nil
+}
+
+func TestLiveReload(t *testing.T) {
+	m, dir := newFileManager(t, nil, map[string]string{"app/prod/testlive.yaml": "host: a\n"})
+	live, err := Watch[testLiveConfig](m, "app", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := live.Load(); got != (testLiveConfig{Host: "a", Port: 80}) {
+		t.Fatalf("initial value = %+v", got)
+	}
+
+	var mu sync.Mutex
+	var calls []testLiveConfig
+	live.OnChange(func(old, new testLiveConfig) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, old, new)
+	})
+
+	rewriteUntil(t, filepath.Join(dir, "app/prod/testlive.yaml"), "host: b\nport: 8080\n", func() bool {
+		return live.Load().Host == "b"
+	}, "live value to swap")
+	if got := live.Load(); got.Port != 8080 {
+		t.Fatalf("value after change = %+v", got)
+	}
+
+	// 等待可能的重复事件处理完成，内容相同的重载不应再次触发回调
+	time.Sleep(3 * fileDebounce)
+	mu.Lock()
+	defer mu.Unlock()
+	want := []testLiveConfig{{Host: "a", Port: 80}, {Host: "b", Port: 8080}}
+	if len(calls) != 2 || calls[0] != want[0] || calls[1] != want[1] {
+		t.Fatalf("callback (old, new) = %+v, want one call with %+v", calls, want)
+	}
+}
+
+func TestLiveRejectsInvalidCandidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing required key", "port: 8080\n"},
+		{"validator fails", "host: b\nport: -1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, dir := newFileManager(t, nil, map[string]string{"app/prod/testlive.yaml": "host: a\n"})
+			live, err := Watch[testLiveConfig](m, "app", "prod")
+			if err != nil {
+				t.Fatal(err)
+			}
+			changed := make(chan struct{}, 1)
+			live.OnChange(func(old, new testLiveConfig) {
+				changed <- struct{}{}
+			})
+
+			var mu sync.Mutex
+			var rejected []error
+			group := m.GetGroup("app", "prod", "testlive")
+			group.OnError(func(err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				rejected = append(rejected, err)
+			})
+
+			rewriteUntil(t, filepath.Join(dir, "app/prod/testlive.yaml"), tt.content, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(rejected) > 0
+			}, "candidate to be rejected")
+
+			if got := live.Load(); got != (testLiveConfig{Host: "a", Port: 80}) {
+				t.Fatalf("value after rejected change = %+v", got)
+			}
+			if got := group.GetString("host"); got != "a" {
+				t.Fatalf("group host after rejected change = %q", got)
+			}
+			select {
+			case <-changed:
+				t.Fatal("OnChange called for rejected candidate")
+			default:
+			}
+		})
+	}
+}
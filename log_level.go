@@ -0,0 +1,240 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LoggerGroup 存放运行时日志级别的配置组名称
+//
+// 配置组内容示例：
+//
+//	level: debug            # 根日志级别，为空时使用启动配置中的 logger.level
+//	loggers:                # 按子日志记录器覆盖级别，名称不区分大小写
+//	  "[etcd client]": debug
+const LoggerGroup = "logger"
+
+// LogLevels 运行时可调整的日志级别，支持按子日志记录器单独设置
+// 子日志记录器由 subLogger 创建，未单独设置时使用根级别
+type LogLevels struct {
+	root zap.AtomicLevel
+	// floor 所有级别中的最低级别，输出端以此过滤，再由各记录器按自身级别过滤
+	floor zap.AtomicLevel
+
+	mu    sync.RWMutex
+	named map[string]zapcore.Level
+}
+
+// newLogLevels 创建以 level 为根级别的日志级别集合
+func newLogLevels(level zapcore.Level) *LogLevels {
+	return &LogLevels{
+		root:  zap.NewAtomicLevelAt(level),
+		floor: zap.NewAtomicLevelAt(level),
+		named: make(map[string]zapcore.Level),
+	}
+}
+
+// Level 返回子日志记录器的当前级别，name 为空时返回根级别
+func (l *LogLevels) Level(name string) zapcore.Level {
+	if name != "" {
+		l.mu.RLock()
+		level, ok := l.named[strings.ToLower(name)]
+		l.mu.RUnlock()
+		if ok {
+			return level
+		}
+	}
+	return l.root.Level()
+}
+
+// SetLevel 设置子日志记录器的级别，name 为空时设置根级别
+func (l *LogLevels) SetLevel(name string, level zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if name == "" {
+		l.root.SetLevel(level)
+	} else {
+		l.named[strings.ToLower(name)] = level
+	}
+	l.updateFloor()
+}
+
+// ResetLevel 移除子日志记录器的单独设置，恢复使用根级别
+func (l *LogLevels) ResetLevel(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.named, strings.ToLower(name))
+	l.updateFloor()
+}
+
+// Levels 返回根级别与所有单独设置的子日志记录器级别，根级别的键为空字符串
+func (l *LogLevels) Levels() map[string]zapcore.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	levels := make(map[string]zapcore.Level, len(l.named)+1)
+	levels[""] = l.root.Level()
+	for name, level := range l.named {
+		levels[name] = level
+	}
+	return levels
+}
+
+// replace 整体替换根级别与子日志记录器级别
+func (l *LogLevels) replace(root zapcore.Level, named map[string]zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.root.SetLevel(root)
+	l.named = make(map[string]zapcore.Level, len(named))
+	for name, level := range named {
+		l.named[strings.ToLower(name)] = level
+	}
+	l.updateFloor()
+}
+
+// updateFloor 重新计算最低级别，调用方需持有 mu
+func (l *LogLevels) updateFloor() {
+	floor := l.root.Level()
+	for _, level := range l.named {
+		floor = min(floor, level)
+	}
+	l.floor.SetLevel(floor)
+}
+
+// logLevelsPayload 日志级别 HTTP 接口的请求与响应
+type logLevelsPayload struct {
+	// Logger 子日志记录器名称，为空表示根级别
+	Logger string `json:"logger,omitempty"`
+	// Level 日志级别，为空且指定 Logger 时移除该记录器的单独设置
+	Level string `json:"level"`
+	// Loggers 各子日志记录器的级别，仅用于响应
+	Loggers map[string]string `json:"loggers,omitempty"`
+}
+
+// ServeHTTP 查询（GET）与设置（PUT/POST）日志级别
+// 通过接口设置的级别在 logger 配置组下次变更时会被覆盖
+func (l *LogLevels) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req logLevelsPayload
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.Logger != "" && req.Level == "" {
+			l.ResetLevel(req.Logger)
+			break
+		}
+		level, err := zapcore.ParseLevel(req.Level)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		l.SetLevel(req.Logger, level)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := logLevelsPayload{Loggers: make(map[string]string)}
+	for name, level := range l.Levels() {
+		if name == "" {
+			resp.Level = level.String()
+		} else {
+			resp.Loggers[name] = level.String()
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// applyGroup 按 logger 配置组设置日志级别，未配置根级别时使用 base
+func (l *LogLevels) applyGroup(group ConfigGroup, base zapcore.Level, logger *zap.SugaredLogger) {
+	root := base
+	if raw := group.GetString("level"); raw != "" {
+		level, err := zapcore.ParseLevel(raw)
+		if err != nil {
+			logger.Warn("忽略无效的日志级别", zap.String("level", raw), zap.Error(err))
+		} else {
+			root = level
+		}
+	}
+
+	named := make(map[string]zapcore.Level)
+	loggers, _ := group.Get("loggers").(map[string]interface{})
+	names := make([]string, 0, len(loggers))
+	for name := range loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		raw := fmt.Sprint(loggers[name])
+		level, err := zapcore.ParseLevel(raw)
+		if err != nil {
+			logger.Warn("忽略无效的日志级别", zap.String("logger", name), zap.String("level", raw), zap.Error(err))
+			continue
+		}
+		named[name] = level
+	}
+
+	l.replace(root, named)
+	logger.Info("日志级别已更新", zap.Stringer("level", root), zap.Any("loggers", named))
+}
+
+// WatchLogLevels 监听 logger 配置组，变更时更新运行时日志级别
+func WatchLogLevels(m *ConfigManager, app, env string, levels *LogLevels, base zapcore.Level) {
+	group := m.GetGroup(app, env, LoggerGroup)
+	levels.applyGroup(group, base, m.logger)
+	group.OnChange(func() {
+		levels.applyGroup(group, base, m.logger)
+	})
+}
+
+// levelCore 按所属子日志记录器的当前级别过滤日志
+type levelCore struct {
+	zapcore.Core
+	levels *LogLevels
+	name   string
+}
+
+// Enabled 判断级别是否启用
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return c.levels.Level(c.name).Enabled(lvl)
+}
+
+// Level 返回当前级别，供 zap.Logger.Level 使用
+func (c *levelCore) Level() zapcore.Level {
+	return c.levels.Level(c.name)
+}
+
+// With 添加字段，保留级别过滤
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels, name: c.name}
+}
+
+// Check 级别启用时交由输出端继续检查
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// subLogger 创建带名称空间的子日志记录器
+// 日志记录器由 NewZapLogger 创建时，子日志记录器的级别可通过 LogLevels 按名称单独调整
+func subLogger(logger *zap.Logger, name string) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if lc, ok := core.(*levelCore); ok {
+			return &levelCore{Core: lc.Core, levels: lc.levels, name: name}
+		}
+		return core
+	})).With(zap.Namespace(name))
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWatchLogLevels(t *testing.T) {
+	m, dir := newFileManager(t, nil, map[string]string{
+		"app/prod/logger.yaml": "level: warn\nloggers:\n  \"[etcd client]\": debug\n  bad: nope\n",
+	})
+	levels := newLogLevels(zapcore.InfoLevel)
+
+	WatchLogLevels(m, "app", "prod", levels, zapcore.InfoLevel)
+	if got := levels.Level(""); got != zapcore.WarnLevel {
+		t.Fatalf("root level = %v, want warn", got)
+	}
+	if got := levels.Level("[ETCD client]"); got != zapcore.DebugLevel {
+		t.Fatalf("named level = %v, want debug", got)
+	}
+	if got := levels.Level("bad"); got != zapcore.WarnLevel {
+		t.Fatalf("invalid named level should fall back to root, got %v", got)
+	}
+
+	rewriteUntil(t, filepath.Join(dir, "app/prod/logger.yaml"), "loggers: {}\n", func() bool {
+		return levels.Level("") == zapcore.InfoLevel && levels.Level("[etcd client]") == zapcore.InfoLevel
+	}, "levels reset to base")
+}
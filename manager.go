@@ -5,11 +5,11 @@ import (
 	"fmt"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
-	_ "github.com/spf13/viper/remote"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,45 +22,98 @@ type (
 		AppConfig *AppConfig
 		Logger    *zap.Logger
 		Client    *clientv3.Client
+		// Keys 可选的密钥提供者（如 KMS），优先于 AppConfig.Encryption
+		Keys KeyProvider `optional:"true"`
 	}
 	// ConfigManager 分布式配置管理器
 	// 提供动态配置加载、监听和管理功能
 	ConfigManager struct {
-		client *clientv3.Client
-		logger *zap.SugaredLogger
-		cfg    EtcdConfig
-		groups map[string]ConfigGroup
-		mu     sync.RWMutex
+		// client etcd 客户端，使用文件后端时为 nil
+		client  *clientv3.Client
+		backend Backend
+		// keys 解密配置组中加密值的密钥提供者，为 nil 时加密值无法解密
+		keys KeyProvider
+		// refs 配置组引用文件的监听，首次出现文件引用时创建
+		refs     *refWatcher
+		refsOnce sync.Once
+		logger   *zap.SugaredLogger
+		cfg      EtcdConfig
+		layers   LayerConfig
+		groups   map[string]ConfigGroup
+		// envOverlay 是否使用环境变量覆盖配置组
+		envOverlay bool
+		// interpolate 是否解析配置组中的变量
+		interpolate bool
+		// secretPatterns 仅作用于本管理器日志的敏感键名模式
+		secretPatterns []string
+		// lifecycle 管理器级协程（引用文件监听）的生命周期，配置组的 ctx 由其 ctx 派生，Stop 时一并取消
+		lifecycle
+		mu sync.RWMutex
 	}
 )
 
 // NewConfigManager 创建配置管理器
-func NewConfigManager(in inParams) *ConfigManager {
-	log := in.Logger.With(zap.Namespace("[ConfigManager]")).Sugar()
-	cfg := in.AppConfig.Etcd
-	return &ConfigManager{
-		client: in.Client,
-		logger: log,
-		groups: make(map[string]ConfigGroup),
-		cfg:    cfg,
+// 根据 AppConfig.Backend 选择配置组后端，配置了加密密钥但无法加载时返回错误
+func NewConfigManager(in inParams) (*ConfigManager, error) {
+	keys := in.Keys
+	if keys == nil {
+		var err error
+		if keys, err = newKeyProvider(in.AppConfig.Encryption); err != nil {
+			return nil, fmt.Errorf("failed to load encryption keys: %w", err)
+		}
 	}
+
+	if !in.AppConfig.Backend.UseEtcd() {
+		backend, err := NewFileBackend(in.AppConfig.Backend.Directory, in.Logger)
+		if err != nil {
+			return nil, err
+		}
+		return newConfigManager(backend, in.Logger, in.AppConfig, keys), nil
+	}
+	m := newConfigManager(NewEtcdBackend(in.Client, in.AppConfig.Etcd, in.Logger), in.Logger, in.AppConfig, keys)
+	m.client = in.Client
+	return m, nil
 }
 
 // NewConfigManagerDirect 创建配置管理器（直接参数）
 func NewConfigManagerDirect(client *clientv3.Client, logger *zap.Logger, appConfig *AppConfig) *ConfigManager {
-	log := logger.With(zap.Namespace("[ConfigManager]")).Sugar()
-	cfg := appConfig.Etcd
+	m := NewConfigManagerWithBackend(NewEtcdBackend(client, appConfig.Etcd, logger), logger, appConfig)
+	m.client = client
+	return m
+}
+
+// NewConfigManagerWithBackend 使用指定的配置组后端创建配置管理器
+// 写入与历史相关的功能依赖 etcd 客户端，使用其他后端时不可用
+// 加密密钥无法加载时仅记录错误，加密值将无法解密
+func NewConfigManagerWithBackend(backend Backend, logger *zap.Logger, appConfig *AppConfig) *ConfigManager {
+	keys, err := newKeyProvider(appConfig.Encryption)
+	m := newConfigManager(backend, logger, appConfig, keys)
+	if err != nil {
+		m.logger.Error("加载加密密钥失败，加密配置值将无法解密", zap.Error(err))
+	}
+	return m
+}
+
+// newConfigManager 使用指定的配置组后端与密钥提供者创建配置管理器
+func newConfigManager(backend Backend, logger *zap.Logger, appConfig *AppConfig, keys KeyProvider) *ConfigManager {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &ConfigManager{
-		client: client,
-		logger: log,
-		groups: make(map[string]ConfigGroup),
-		cfg:    cfg,
+		lifecycle:      lifecycle{ctx: ctx, cancel: cancel},
+		backend:        backend,
+		logger:         subLogger(logger, "[ConfigManager]").Sugar(),
+		groups:         make(map[string]ConfigGroup),
+		cfg:            appConfig.Etcd,
+		layers:         appConfig.Layers,
+		envOverlay:     appConfig.EnvOverlay,
+		interpolate:    appConfig.Interpolation,
+		keys:           keys,
+		secretPatterns: slices.Clone(appConfig.SecretPatterns),
 	}
 }
 
 // GetGroup 获取配置组（不存在则创建）
 func (m *ConfigManager) GetGroup(app, env, group string) ConfigGroup {
-	key := fmt.Sprintf("%s/%s/%s/%s/content.yaml", m.cfg.Prefix, app, env, group)
+	key := m.backend.Key(app, env, group)
 
 	m.mu.RLock()
 	if g, exists := m.groups[key]; exists {
@@ -69,65 +122,110 @@ func (m *ConfigManager) GetGroup(app, env, group string) ConfigGroup {
 	}
 	m.mu.RUnlock()
 
-	// 创建新配置组
-	v := viper.New()
-	v.SetConfigType("yaml")
-
-	// 添加 etcd 远程提供者
-	if err := v.AddRemoteProvider("etcd3",
-		m.client.Endpoints()[0], key); err != nil {
-		m.logger.Fatal("添加远程提供者失败", zap.Error(err))
+	g := &configGroup{
+		logger:     m.logger.With(zap.String("group", key)),
+		groupKey:   key,
+		name:       group,
+		app:        app,
+		env:        env,
+		watchers:   []func(){},
+		validators: make(map[reflect.Type]validatorFunc),
+		layerState: layerState{layers: m.newLayers(app, env, group)},
 	}
 
-	// 初始读取
-	if err := v.ReadRemoteConfig(); err != nil {
-		m.logger.Warn("读取远程配置失败，使用空配置",
-			zap.String("key", key), zap.Error(err))
+	// 初始读取，失败时回退到本地快照
+	v, revision, err := m.loadLayers(g)
+	if err != nil {
+		g.stale = true
+		if snapshot, snapErr := m.loadSnapshot(key); snapErr == nil {
+			m.logger.Warn("读取远程配置失败，使用本地快照",
+				zap.String("key", key), zap.Error(err))
+			v = snapshot
+		} else {
+			m.logger.Warn("读取远程配置失败，使用空配置",
+				zap.String("key", key), zap.Error(err), zap.NamedError("snapshot", snapErr))
+			v = viper.New()
+		}
+	} else {
+		m.saveSnapshot(key, v)
 	}
-
-	g := &etcdConfigGroup{
-		viper:    v,
-		logger:   m.logger.With(zap.String("group", key)),
-		groupKey: key,
-		watchers: []func(){},
+	// 快照保存原始内容，配置组中保存解密并解析引用后的值
+	g.raw = v
+	plain, refs, err := m.materialize(g, v)
+	if err != nil {
+		m.logger.Error("解析配置失败，加密值与引用保持原样", zap.String("key", key), zap.Error(err))
+		g.materializeErr = fmt.Errorf("config %s: %w", key, err)
+	} else {
+		v = plain
 	}
+	g.hasRefs = len(refs.files) > 0
+	g.viper = v
+	g.revision = revision
 
-	// 注册到管理器
+	// 注册到管理器，并发创建时以先注册者为准
 	m.mu.Lock()
+	if existing, exists := m.groups[key]; exists {
+		m.mu.Unlock()
+		return existing
+	}
+	g.ctx, g.cancel = context.WithCancel(m.ctx)
 	m.groups[key] = g
 	m.mu.Unlock()
+	if g.hasRefs {
+		m.trackRefs(g, refs.files)
+	}
+	m.trackGroupRefs(g, refs.groups)
 
 	// 启动该配置组的动态监听
-	go m.watchGroup(g)
+	m.watchGroup(g)
+	if g.stale {
+		g.spawn(func() { m.reconcile(g) })
+	}
 
 	return g
 }
 
-// watchGroup 监听配置组变更
-func (m *ConfigManager) watchGroup(g *etcdConfigGroup) {
-	watchKey := g.groupKey + "/"
-
-	watchChan := m.client.Watch(context.Background(), watchKey,
-		clientv3.WithPrefix())
+// groupKey 返回配置组在 etcd 中的前缀
+func (m *ConfigManager) groupKey(app, env, group string) string {
+	return etcdGroupKey(m.cfg.Prefix, app, env, group)
+}
 
-	g.logger.Info("开始监听配置变更", zap.String("watch_key", watchKey))
+// applyCandidate 校验并替换配置组的候选配置，成功后更新快照并通知监听者
+func (m *ConfigManager) applyCandidate(g *configGroup, candidate *viper.Viper, revision int64) {
+	m.apply(g, candidate, revision, true)
+}
 
-	for watchResp := range watchChan {
-		for _, event := range watchResp.Events {
-			g.logger.Info("配置变更事件",
-				zap.String("key", string(event.Kv.Key)),
-				zap.String("value", string(event.Kv.Value)))
+// apply 解密并解析候选配置中的引用，校验通过后替换
+// synced 表示候选配置来自后端，替换后清除配置组的 stale 状态；调用方需持有 g.applyMu
+func (m *ConfigManager) apply(g *configGroup, candidate *viper.Viper, revision int64, synced bool) {
+	plain, refs, err := m.materialize(g, candidate)
+	if err != nil {
+		g.logger.Error("解析配置失败，保留上一个有效版本", zap.Error(err))
+		g.notifyErrors(fmt.Errorf("config %s rejected: %w", g.groupKey, err))
+		return
+	}
 
-			// 重新读取配置
-			if err := g.viper.ReadRemoteConfig(); err != nil {
-				g.logger.Error("重新读取配置失败", zap.Error(err))
-				continue
-			}
+	// 校验通过后再替换，失败时保留上一个有效版本
+	if err := g.validate(plain); err != nil {
+		g.logger.Error("配置校验失败，保留上一个有效版本", zap.Error(err))
+		g.notifyErrors(fmt.Errorf("config %s rejected: %w", g.groupKey, err))
+		return
+	}
+	changes := g.swap(candidate, plain, revision, synced)
+	if hadRefs := g.setHasRefs(len(refs.files) > 0); hadRefs || len(refs.files) > 0 {
+		m.trackRefs(g, refs.files)
+	}
+	m.trackGroupRefs(g, refs.groups)
+	// 快照保存原始内容，避免解密后的明文写入本地磁盘
+	m.saveSnapshot(g.groupKey, candidate)
 
-			// 通知监听者
-			g.notifyWatchers()
-		}
+	// 内容未变化时不通知，例如重新解析引用或版本被压缩后按相同内容重新应用
+	if changes.Empty() {
+		return
 	}
+
+	// 通知监听者
+	g.notifyWatchers(changes)
 }
 
 // StartWatching 启动所有配置组的监听（fx.Invoke 调用）
@@ -135,17 +233,32 @@ func (m *ConfigManager) StartWatching() {
 	m.logger.Info("配置管理器启动动态监听")
 }
 
-// Stop 停止所有监听（fx 生命周期）
-func (m *ConfigManager) Stop(ctx context.Context) error {
-	m.logger.Info("配置管理器停止")
-	return m.client.Close()
-}
-
 // GetConfig 根据泛型类型自动获取配置
 // 规则：将结构体名称转为小写并移除末尾的 "config" 后缀
 func GetConfig[T any](m *ConfigManager, app, env string) (T, error) {
 	var config T
 
+	// 获取配置组
+	configGroup := m.GetGroup(app, env, groupNameOf[T]())
+	registerValidator[T](configGroup)
+	bindSecretPaths(configGroup, reflect.TypeOf((*T)(nil)).Elem())
+	m.bindGroupEnv(configGroup, reflect.TypeOf((*T)(nil)).Elem())
+
+	// 将配置反序列化到目标类型
+	err := configGroup.Unmarshal(&config)
+	if err != nil {
+		return config, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	if err := validateValue(&config); err != nil {
+		return config, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return config, nil
+}
+
+// groupNameOf 根据泛型类型推导配置组名称
+func groupNameOf[T any]() string {
 	// 获取类型名称 - 使用零值来获取类型信息
 	typeOf := reflect.TypeOf((*T)(nil)).Elem()
 
@@ -160,17 +273,7 @@ func GetConfig[T any](m *ConfigManager, app, env string) (T, error) {
 	if strings.HasSuffix(groupName, "config") {
 		groupName = groupName[:len(groupName)-6] // "config" 的长度是6
 	}
-
-	// 获取配置组
-	configGroup := m.GetGroup(app, env, groupName)
-
-	// 将配置反序列化到目标类型
-	err := configGroup.Unmarshal(&config)
-	if err != nil {
-		return config, fmt.Errorf("failed to unmarshal config: %w", err)
-	}
-
-	return config, nil
+	return groupName
 }
 
 // ConfigGroup 配置组接口
@@ -188,26 +291,64 @@ type ConfigGroup interface {
 	Unmarshal(obj interface{}) error
 	// OnChange 注册配置变更回调函数
 	OnChange(fn func())
+	// OnChangeEvent 注册带差异信息的配置变更回调函数
+	OnChangeEvent(fn func(event ChangeEvent))
+	// OnKeyChange 注册仅在指定点分路径前缀下的键变更时触发的回调函数
+	OnKeyChange(prefix string, fn func(event ChangeEvent))
+	// OnError 注册配置变更被拒绝时的回调函数
+	OnError(fn func(err error))
+	// Stale 当前配置是否来自本地快照或空配置，尚未与 etcd 同步
+	Stale() bool
+	// WatchState 返回配置组的监听状态，用于健康检查
+	WatchState() WatchState
 }
 
-// etcdConfigGroup 基于 etcd 的配置组实现
-type etcdConfigGroup struct {
-	viper    *viper.Viper
+// configGroup 配置组实现，内容由 Backend 提供
+type configGroup struct {
+	viper *viper.Viper
+	// raw 解密与解析引用之前的原始配置
+	raw *viper.Viper
+	// materializeErr 初始配置中的加密值或引用无法解析时的错误，Unmarshal 时返回
+	materializeErr error
+	// hasRefs 当前配置是否引用了文件
+	hasRefs bool
+	// depends 已订阅变更的被引用配置组
+	depends map[string]bool
+	// envKeys 通过 GetConfig 绑定的结构体键，开启 EnvOverlay 时即使配置组中缺失也可由环境变量提供
+	envKeys map[string]bool
+	// secretPaths 通过 GetConfig 或 Watch 绑定的结构体中 secret:"true" 字段的路径，用于隐藏变更日志中的值
+	secretPaths map[string]bool
+	// app、env 配置组所属的应用与环境，用于变量解析
+	app      string
+	env      string
 	logger   *zap.SugaredLogger
 	groupKey string // 例如: /configs/myapp/prod/database
+	name     string // 配置组名称，例如: database
 	watchers []func()
-	mu       sync.RWMutex
+	// revision 当前生效配置的修改版本号
+	revision    int64
+	subscribers []eventSubscriber
+	// stale 配置尚未与 etcd 同步（来自本地快照或空配置）
+	stale bool
+	// layerState 分层配置的各层内容
+	layerState
+	// lifecycle 监听协程与回调的生命周期
+	lifecycle
+	// validators 按目标类型注册的校验函数
+	validators  map[reflect.Type]validatorFunc
+	errHandlers []func(err error)
+	mu          sync.RWMutex
 }
 
 // Get 获取原始值
-func (g *etcdConfigGroup) Get(key string) interface{} {
+func (g *configGroup) Get(key string) interface{} {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 	return g.viper.Get(key)
 }
 
 // GetString 获取字符串
-func (g *etcdConfigGroup) GetString(key string) string {
+func (g *configGroup) GetString(key string) string {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
@@ -222,7 +363,7 @@ func (g *etcdConfigGroup) GetString(key string) string {
 }
 
 // GetInt 获取整数
-func (g *etcdConfigGroup) GetInt(key string) int {
+func (g *configGroup) GetInt(key string) int {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
@@ -249,7 +390,7 @@ func (g *etcdConfigGroup) GetInt(key string) int {
 }
 
 // GetBool 获取布尔值
-func (g *etcdConfigGroup) GetBool(key string) bool {
+func (g *configGroup) GetBool(key string) bool {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
@@ -275,26 +416,131 @@ func (g *etcdConfigGroup) GetBool(key string) bool {
 }
 
 // Unmarshal 反序列化到结构体
-func (g *etcdConfigGroup) Unmarshal(obj interface{}) error {
+func (g *configGroup) Unmarshal(obj interface{}) error {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
-	return g.viper.Unmarshal(obj, func(config *mapstructure.DecoderConfig) {
-		config.TagName = "mapstructure" // 使用 mapstructure tag
-	})
+	if g.materializeErr != nil {
+		return g.materializeErr
+	}
+	return unmarshalViper(g.viper, obj)
+}
+
+// unmarshalViper 使用统一的解码选项反序列化 viper 配置
+// 目标类型中的 default 与 required 标签在此生效，见 decodeWithDefaults
+func unmarshalViper(v *viper.Viper, obj interface{}) error {
+	return decodeWithDefaults(v, obj)
+}
+
+// decoderOptions 统一的解码选项
+func decoderOptions(config *mapstructure.DecoderConfig) {
+	config.TagName = "mapstructure" // 使用 mapstructure tag
 }
 
 // OnChange 注册配置变更回调
-func (g *etcdConfigGroup) OnChange(fn func()) {
+func (g *configGroup) OnChange(fn func()) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	g.watchers = append(g.watchers, fn)
 }
 
+// OnChangeEvent 注册带差异信息的配置变更回调
+func (g *configGroup) OnChangeEvent(fn func(event ChangeEvent)) {
+	g.OnKeyChange("", fn)
+}
+
+// OnKeyChange 注册指定路径前缀下的配置变更回调
+func (g *configGroup) OnKeyChange(prefix string, fn func(event ChangeEvent)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.subscribers = append(g.subscribers, eventSubscriber{prefix: prefix, fn: fn})
+}
+
 // notifyWatchers 通知所有监听者
-func (g *etcdConfigGroup) notifyWatchers() {
+func (g *configGroup) notifyWatchers(event ChangeEvent) {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 	for _, fn := range g.watchers {
-		go fn() // 异步执行避免阻塞
+		g.spawn(fn) // 异步执行避免阻塞
+	}
+	for _, sub := range g.subscribers {
+		filtered := event.filter(sub.prefix)
+		if sub.prefix != "" && filtered.Empty() {
+			continue
+		}
+		g.spawn(func() { sub.fn(filtered) })
 	}
 }
+
+// OnError 注册配置变更被拒绝时的回调
+func (g *configGroup) OnError(fn func(err error)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.errHandlers = append(g.errHandlers, fn)
+}
+
+// notifyErrors 通知所有错误回调
+func (g *configGroup) notifyErrors(err error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	for _, fn := range g.errHandlers {
+		g.spawn(func() { fn(err) })
+	}
+}
+
+// addValidator 注册目标类型的校验函数（同一类型只注册一次）
+func (g *configGroup) addValidator(t reflect.Type, fn validatorFunc) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, exists := g.validators[t]; !exists {
+		g.validators[t] = fn
+	}
+}
+
+// validate 使用已注册的校验函数校验候选配置
+func (g *configGroup) validate(candidate *viper.Viper) error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	for _, fn := range g.validators {
+		if err := fn(candidate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// swap 替换当前生效的配置与其原始配置，返回新旧配置之间的变更事件
+func (g *configGroup) swap(raw, v *viper.Viper, revision int64, synced bool) ChangeEvent {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	event := ChangeEvent{
+		Group:       g.groupKey,
+		OldRevision: g.revision,
+		NewRevision: revision,
+		ChangeSet:   diffSettings(g.viper.AllSettings(), v.AllSettings()),
+	}
+	g.raw = raw
+	g.viper = v
+	g.revision = revision
+	g.materializeErr = nil
+	if synced {
+		g.stale = false
+	}
+	return event
+}
+
+// setHasRefs 更新配置组是否引用了文件，返回更新前的值
+func (g *configGroup) setHasRefs(hasRefs bool) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	old := g.hasRefs
+	g.hasRefs = hasRefs
+	return old
+}
+
+// Stale 当前配置是否尚未与 etcd 同步
+func (g *configGroup) Stale() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.stale
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// newFileManager 创建使用临时目录文件后端的配置管理器，files 为相对路径（<app>/<env>/<group>.yaml）到内容的映射
+func newFileManager(t *testing.T, cfg *AppConfig, files map[string]string) (*ConfigManager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for rel, content := range files {
+		writeTestFile(t, filepath.Join(dir, rel), content)
+	}
+
+	backend, err := NewFileBackend(dir, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg == nil {
+		cfg = &AppConfig{}
+	}
+	m := NewConfigManagerWithBackend(backend, zap.NewNop(), cfg)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := m.Stop(ctx); err != nil {
+			t.Errorf("Stop: %v", err)
+		}
+	})
+	return m, dir
+}
+
+// writeTestFile 写入文件，必要时创建目录
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// rewriteUntil 反复写入文件直到条件满足
+// 后台监听建立的时机不可观测，单次写入可能早于目录监听而被错过
+func rewriteUntil(t *testing.T, path, content string, cond func() bool, msg string) {
+	t.Helper()
+	repeatUntil(t, func() { writeTestFile(t, path, content) }, cond, msg)
+}
+
+// repeatUntil 反复执行 action 直到条件满足
+func repeatUntil(t *testing.T, action func(), cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		action()
+		for poll := time.Now().Add(200 * time.Millisecond); time.Now().Before(poll); time.Sleep(20 * time.Millisecond) {
+			if cond() {
+				return
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", msg)
+		}
+	}
+}
+
+// eventually 在超时前反复检查条件
+func eventually(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", msg)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestFileBackendHotReload(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial map[string]string
+		update  func(t *testing.T, path string)
+	}{
+		{
+			name:    "write in place",
+			initial: map[string]string{"app/prod/database.yaml": "host: a\nport: 5432\n"},
+			update: func(t *testing.T, path string) {
+				writeTestFile(t, path, "host: b\nport: 5432\n")
+			},
+		},
+		{
+			name:    "editor rename",
+			initial: map[string]string{"app/prod/database.yaml": "host: a\nport: 5432\n"},
+			update: func(t *testing.T, path string) {
+				tmp := path + ".swp"
+				writeTestFile(t, tmp, "host: b\nport: 5432\n")
+				if err := os.Rename(tmp, path); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "file created after start",
+			update: func(t *testing.T, path string) {
+				writeTestFile(t, path, "host: b\nport: 5432\n")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, dir := newFileManager(t, nil, tt.initial)
+			g := m.GetGroup("app", "prod", "database")
+
+			events := make(chan ChangeEvent, 16)
+			g.OnChangeEvent(func(event ChangeEvent) { events <- event })
+
+			path := filepath.Join(dir, "app/prod/database.yaml")
+			repeatUntil(t, func() { tt.update(t, path) }, func() bool {
+				return g.GetString("host") == "b"
+			}, "config to reload")
+			if got := g.GetInt("port"); got != 5432 {
+				t.Fatalf("port = %d, want 5432", got)
+			}
+			if g.Stale() {
+				t.Fatal("group still stale after reload")
+			}
+
+			select {
+			case event := <-events:
+				if !event.Affects("host") || event.NewRevision == 0 {
+					t.Fatalf("change event = %+v", event)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("no change event")
+			}
+		})
+	}
+}
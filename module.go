@@ -1,6 +1,8 @@
 package config
 
 import (
+	"context"
+
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -22,6 +24,18 @@ func NewConfigModule() fx.Option {
 				func(manager *ConfigManager) {
 					manager.StartWatching()
 				},
+				// 按 logger 配置组调整运行时日志级别
+				// 启动后在管理器生命周期内后台读取，后端不可用时不阻塞应用构建与启动
+				func(lc fx.Lifecycle, cfg *AppConfig, manager *ConfigManager, levels *LogLevels) {
+					lc.Append(fx.Hook{
+						OnStart: func(context.Context) error {
+							manager.spawn(func() {
+								WatchLogLevels(manager, cfg.AppName, cfg.Env, levels, toLevel(cfg.Logger.Level))
+							})
+							return nil
+						},
+					})
+				},
 			),
 			fx.Decorate(
 				// 自动注册停止钩子
@@ -36,10 +50,23 @@ func NewConfigModule() fx.Option {
 }
 
 // 内部辅助函数
-func newZapLoggerFromAppConfig(cfg *AppConfig) *zap.Logger {
-	return NewZapLogger(cfg.Logger)
+func newZapLoggerFromAppConfig(cfg *AppConfig) (*zap.Logger, *LogLevels) {
+	return NewZapLoggerWithLevels(cfg.Logger)
 }
 
-func newEtcdClientFromAppConfig(cfg *AppConfig, logger *zap.Logger) (*clientv3.Client, error) {
-	return NewEtcdClient(cfg.Etcd, logger)
+// 使用文件后端时不创建 etcd 客户端，连通性检查在 OnStart 中进行
+func newEtcdClientFromAppConfig(lifecycle fx.Lifecycle, cfg *AppConfig, logger *zap.Logger) (*clientv3.Client, error) {
+	if !cfg.Backend.UseEtcd() {
+		return nil, nil
+	}
+	client, err := NewEtcdClient(cfg.Etcd, logger)
+	if err != nil {
+		return nil, err
+	}
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return startEtcdClient(ctx, client, cfg.Etcd, logger)
+		},
+	})
+	return client, nil
 }
@@ -0,0 +1,374 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+	"go.yaml.in/yaml/v3"
+)
+
+// ErrRevisionConflict 写入时配置组的版本号与期望不一致
+var ErrRevisionConflict = errors.New("config revision conflict")
+
+// defaultRequestTimeout 未配置 DialTimeout 时单次请求的超时时间
+const defaultRequestTimeout = 5 * time.Second
+
+type (
+	// PutOption 写入配置选项
+	PutOption func(*putOptions)
+
+	putOptions struct {
+		checkRevision  bool
+		expectRevision int64
+		author         string
+		comment        string
+	}
+)
+
+// WithExpectedRevision 仅当配置组当前的修改版本号等于 rev 时才写入
+// rev 为 0 表示仅在配置组不存在时写入
+func WithExpectedRevision(rev int64) PutOption {
+	return func(o *putOptions) {
+		o.checkRevision = true
+		o.expectRevision = rev
+	}
+}
+
+// WithAuthor 设置写入操作的作者，记录到审计历史中
+func WithAuthor(author string) PutOption {
+	return func(o *putOptions) {
+		o.author = author
+	}
+}
+
+// WithComment 设置写入操作的说明，记录到审计历史中
+func WithComment(comment string) PutOption {
+	return func(o *putOptions) {
+		o.comment = comment
+	}
+}
+
+// Put 将结构体或 map 序列化后写入配置组
+// 写入的键与 GetGroup 读取的键一致，并沿用配置组已有的格式（默认 YAML），
+// 拆分布局的配置组请使用 PutValue，返回写入后的修改版本号
+func (m *ConfigManager) Put(app, env, group string, value interface{}, opts ...PutOption) (int64, error) {
+	layout, err := m.contentLayout(app, env, group)
+	if err != nil {
+		return 0, err
+	}
+
+	content, err := marshalContent(value, layout.format)
+	if err != nil {
+		return 0, err
+	}
+
+	return m.put(app, env, group, layout.key, content, opts...)
+}
+
+// PutValue 写入拆分布局配置组中的单个叶子值，path 为点分路径
+// 配置组不存在时以拆分布局创建，WithExpectedRevision 比较的是该叶子键的版本号
+func (m *ConfigManager) PutValue(app, env, group, path string, value interface{}, opts ...PutOption) (int64, error) {
+	if err := m.requireClient(); err != nil {
+		return 0, err
+	}
+
+	base := m.groupKey(app, env, group)
+	ctx, cancel := m.requestContext()
+	defer cancel()
+
+	layout, err := detectLayout(ctx, m.client, base)
+	if err != nil {
+		return 0, err
+	}
+	if !layout.exploded {
+		if rev, err := m.client.Get(ctx, layout.key, clientv3.WithKeysOnly()); err != nil {
+			return 0, fmt.Errorf("failed to get config %s: %w", layout.key, err)
+		} else if len(rev.Kvs) > 0 {
+			return 0, fmt.Errorf("%w: config %s is stored in %s, use Put instead",
+				errors.ErrUnsupported, base, layout.key)
+		}
+	}
+
+	var o putOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	raw, err := yaml.Marshal(value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal config value: %w", err)
+	}
+
+	key := explodedKey(base, path)
+	txn := m.client.Txn(ctx)
+	if o.checkRevision {
+		txn = txn.If(clientv3.Compare(clientv3.ModRevision(key), "=", o.expectRevision))
+	}
+	resp, err := txn.Then(clientv3.OpPut(key, strings.TrimSpace(string(raw)))).Commit()
+	if err != nil {
+		return 0, fmt.Errorf("failed to put config %s: %w", key, err)
+	}
+	if !resp.Succeeded {
+		return 0, fmt.Errorf("%w: key %s expected revision %d", ErrRevisionConflict, key, o.expectRevision)
+	}
+
+	m.logger.Info("配置值写入成功", zap.String("key", key), zap.Int64("revision", resp.Header.Revision))
+	return resp.Header.Revision, nil
+}
+
+// contentLayout 获取单键布局配置组的内容键与格式，拆分布局返回错误
+func (m *ConfigManager) contentLayout(app, env, group string) (groupLayout, error) {
+	if err := m.requireClient(); err != nil {
+		return groupLayout{}, err
+	}
+
+	base := m.groupKey(app, env, group)
+	ctx, cancel := m.requestContext()
+	defer cancel()
+
+	layout, err := detectLayout(ctx, m.client, base)
+	if err != nil {
+		return groupLayout{}, err
+	}
+	if layout.exploded {
+		return groupLayout{}, fmt.Errorf("%w: config %s uses exploded layout, use PutValue instead",
+			errors.ErrUnsupported, base)
+	}
+	return layout, nil
+}
+
+// put 写入原始配置内容，并在同一事务中写入审计记录
+func (m *ConfigManager) put(app, env, group, key string, content []byte, opts ...PutOption) (int64, error) {
+	if err := m.requireClient(); err != nil {
+		return 0, err
+	}
+
+	o := putOptions{author: defaultAuthor()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	audit, err := newAuditRecord(o, content)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := m.requestContext()
+	defer cancel()
+
+	txn := m.client.Txn(ctx)
+	if o.checkRevision {
+		txn = txn.If(clientv3.Compare(clientv3.ModRevision(key), "=", o.expectRevision))
+	}
+	resp, err := txn.Then(
+		clientv3.OpPut(key, string(content)),
+		clientv3.OpPut(m.historyKey(app, env, group)+"/"+auditID(), string(audit)),
+	).
+		Else(clientv3.OpGet(key, clientv3.WithKeysOnly())).
+		Commit()
+	if err != nil {
+		return 0, fmt.Errorf("failed to put config %s: %w", key, err)
+	}
+
+	if !resp.Succeeded {
+		current := int64(0)
+		if rr := resp.Responses[0].GetResponseRange(); rr != nil && len(rr.Kvs) > 0 {
+			current = rr.Kvs[0].ModRevision
+		}
+		return current, fmt.Errorf("%w: key %s expected revision %d, current %d",
+			ErrRevisionConflict, key, o.expectRevision, current)
+	}
+
+	m.logger.Info("配置写入成功",
+		zap.String("key", key),
+		zap.Int64("revision", resp.Header.Revision),
+		zap.String("author", o.author))
+
+	m.pruneHistory(app, env, group)
+	return resp.Header.Revision, nil
+}
+
+// PutTyped 根据泛型类型推导配置组名称并写入配置
+// 配置组名称规则与 GetConfig 相同
+func PutTyped[T any](m *ConfigManager, app, env string, value T, opts ...PutOption) (int64, error) {
+	return m.Put(app, env, groupNameOf[T](), value, opts...)
+}
+
+// Revision 获取配置组当前的修改版本号，配置组不存在时返回 0
+func (m *ConfigManager) Revision(app, env, group string) (int64, error) {
+	layout, err := m.contentLayout(app, env, group)
+	if err != nil {
+		return 0, err
+	}
+
+	key := layout.key
+	ctx, cancel := m.requestContext()
+	defer cancel()
+
+	resp, err := m.client.Get(ctx, key, clientv3.WithKeysOnly())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get config revision %s: %w", key, err)
+	}
+	if len(resp.Kvs) == 0 {
+		return 0, nil
+	}
+	return resp.Kvs[0].ModRevision, nil
+}
+
+// requestContext 创建单次 etcd 请求的超时上下文
+func (m *ConfigManager) requestContext() (context.Context, context.CancelFunc) {
+	return requestContext(m.cfg)
+}
+
+// requireClient 写入与历史相关的功能仅在 etcd 后端下可用
+func (m *ConfigManager) requireClient() error {
+	if m.client == nil {
+		return fmt.Errorf("%w: etcd client is not configured", errors.ErrUnsupported)
+	}
+	return nil
+}
+
+// requestContext 根据 etcd 配置创建单次请求的超时上下文
+func requestContext(cfg EtcdConfig) (context.Context, context.CancelFunc) {
+	timeout := cfg.DialTimeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// marshalContent 将配置值按格式序列化
+// 结构体按 mapstructure 标签转换为键名，保证与读取时的映射一致
+func marshalContent(value interface{}, format string) ([]byte, error) {
+	settings, err := toSettings(value)
+	if err != nil {
+		return nil, err
+	}
+	content, err := encodeContent(settings, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return content, nil
+}
+
+// toSettings 将结构体或 map 转换为配置树
+func toSettings(value interface{}) (map[string]interface{}, error) {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("config value is nil")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct && rv.Kind() != reflect.Map {
+		return nil, fmt.Errorf("config value must be a struct or map, got %s", rv.Type())
+	}
+
+	settings, ok := encodeValue(rv).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("config value must be a struct or map, got %s", rv.Type())
+	}
+	return settings, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// encodeValue 递归地将值转换为可序列化的基础类型
+func encodeValue(rv reflect.Value) interface{} {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Type() == durationType {
+		return time.Duration(rv.Int()).String()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		out := make(map[string]interface{})
+		encodeStruct(rv, out)
+		return out
+	case reflect.Map:
+		out := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = encodeValue(iter.Value())
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		out := make([]interface{}, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			out[i] = encodeValue(rv.Index(i))
+		}
+		return out
+	default:
+		return rv.Interface()
+	}
+}
+
+// encodeStruct 按 mapstructure 标签将结构体字段写入 map
+func encodeStruct(rv reflect.Value, out map[string]interface{}) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts := parseTag(field)
+		if name == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if opts.squash {
+			for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				encodeStruct(fv, out)
+				continue
+			}
+		}
+		if opts.omitempty && fv.IsZero() {
+			continue
+		}
+		out[name] = encodeValue(fv)
+	}
+}
+
+type tagOptions struct {
+	squash    bool
+	omitempty bool
+}
+
+// parseTag 解析字段的 mapstructure 标签，未设置时使用小写字段名
+func parseTag(field reflect.StructField) (string, tagOptions) {
+	var opts tagOptions
+	parts := strings.Split(field.Tag.Get("mapstructure"), ",")
+	for _, p := range parts[1:] {
+		switch p {
+		case "squash":
+			opts.squash = true
+		case "omitempty":
+			opts.omitempty = true
+		}
+	}
+	name := parts[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, opts
+}
@@ -0,0 +1,311 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// RedactedValue 敏感值在日志与格式化输出中的替代文本
+const RedactedValue = "******"
+
+// defaultSecretPatterns 默认的敏感键名模式
+// 键名同时以小写形式和移除 "_" 与 "-" 后的形式参与匹配，例如 access_key_secret 也按 accesskeysecret 匹配
+var defaultSecretPatterns = []string{
+	"*password*", "*passwd*", "*secret*", "*token",
+	"*signingkey*", "*apikey*", "*privatekey*", "*accesskeysecret*",
+	"*mchntkey*", "*aeskey*", "*credential*",
+}
+
+var (
+	secretMu       sync.RWMutex
+	secretPatterns = append([]string(nil), defaultSecretPatterns...)
+)
+
+// AddSecretPatterns 全局添加敏感键名模式（path.Match 语法，按小写键名匹配），匹配的键在日志与格式化输出中被隐藏
+// 仅需作用于单个配置管理器日志的模式使用 AppConfig.SecretPatterns
+func AddSecretPatterns(patterns ...string) {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	for _, p := range patterns {
+		if !slices.Contains(secretPatterns, p) {
+			secretPatterns = append(secretPatterns, p)
+		}
+	}
+}
+
+// IsSecretKey 判断点分路径的最后一段是否为敏感键名
+func IsSecretKey(key string) bool {
+	return matchSecretKey(key, nil)
+}
+
+// matchSecretKey 按全局模式与 extra 判断点分路径的最后一段是否为敏感键名
+func matchSecretKey(key string, extra []string) bool {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		key = key[i+1:]
+	}
+	lower := strings.ToLower(key)
+	name := normalizeSecretKey(key)
+	matches := func(patterns []string) bool {
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, name); ok {
+				return true
+			}
+			if ok, _ := path.Match(p, lower); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	if matches(extra) {
+		return true
+	}
+	secretMu.RLock()
+	defer secretMu.RUnlock()
+	return matches(secretPatterns)
+}
+
+// normalizeSecretKey 键名转为小写并移除分隔符
+func normalizeSecretKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+}
+
+// matchSecretPath 判断点分路径是否为 paths 中的敏感字段或其子键
+// paths 中的 "*" 匹配任意一段，用于映射的键
+func matchSecretPath(key string, paths []string) bool {
+	segments := strings.Split(strings.ToLower(key), ".")
+	for _, p := range paths {
+		parts := strings.Split(p, ".")
+		if len(parts) > len(segments) {
+			continue
+		}
+		matched := true
+		for i, part := range parts {
+			if part != "*" && part != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// secretFieldPaths 列出结构体中带有 secret:"true" 标签的字段路径
+// 切片元素与所在字段共用路径，映射的键以 "*" 表示
+func secretFieldPaths(t reflect.Type, prefix string, visiting map[reflect.Type]bool) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return secretFieldPaths(t.Elem(), prefix, visiting)
+	case reflect.Map:
+		return secretFieldPaths(t.Elem(), joinKey(prefix, "*"), visiting)
+	case reflect.Struct:
+	default:
+		return nil
+	}
+	if visiting[t] {
+		return nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var paths []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, opts := parseTag(field)
+		if name == "-" {
+			continue
+		}
+		path := prefix
+		if !opts.squash {
+			path = joinKey(prefix, strings.ToLower(name))
+		}
+		if field.Tag.Get("secret") == "true" {
+			paths = append(paths, path)
+			continue
+		}
+		paths = append(paths, secretFieldPaths(field.Type, path, visiting)...)
+	}
+	return paths
+}
+
+// bindSecretPaths 将目标结构体的敏感字段路径加入配置组，用于隐藏变更日志中的值
+func bindSecretPaths(group ConfigGroup, t reflect.Type) {
+	g, ok := group.(*configGroup)
+	if !ok {
+		return
+	}
+	paths := secretFieldPaths(t, "", make(map[reflect.Type]bool))
+	if len(paths) == 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.secretPaths == nil {
+		g.secretPaths = make(map[string]bool)
+	}
+	for _, p := range paths {
+		g.secretPaths[p] = true
+	}
+}
+
+// boundSecretPaths 返回配置组已绑定的敏感字段路径
+func (g *configGroup) boundSecretPaths() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	paths := make([]string, 0, len(g.secretPaths))
+	for p := range g.secretPaths {
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// RedactSettings 返回隐藏了敏感键的配置树副本
+func RedactSettings(settings map[string]interface{}) map[string]interface{} {
+	return redactSettings(settings, "", nil, nil)
+}
+
+// redactSettings 按全局模式与 extra 隐藏敏感键，按 paths 隐藏敏感字段，prefix 为配置树所在的路径
+func redactSettings(settings map[string]interface{}, prefix string, extra, paths []string) map[string]interface{} {
+	out := make(map[string]interface{}, len(settings))
+	for k, v := range settings {
+		out[k] = redactValue(joinKey(prefix, k), v, extra, paths)
+	}
+	return out
+}
+
+// redactValue 隐藏敏感键的叶子值，子树递归处理，key 为完整的点分路径
+func redactValue(key string, value interface{}, extra, paths []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return redactSettings(v, key, extra, paths)
+	case map[interface{}]interface{}:
+		children := make(map[string]interface{}, len(v))
+		for ck, cv := range v {
+			children[toString(ck)] = cv
+		}
+		return redactSettings(children, key, extra, paths)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = redactValue(key, item, extra, paths)
+		}
+		return items
+	default:
+		if value != nil && (matchSecretKey(key, extra) || matchSecretPath(key, paths)) {
+			return RedactedValue
+		}
+		return value
+	}
+}
+
+// redactContent 返回可安全写入日志的内容键值
+// extra 为全局模式之外的敏感键名模式，paths 为已绑定结构体的敏感字段路径
+// 拆分布局的叶子键按键名与路径判断，单键内容解析后隐藏敏感键，无法解析时只输出长度
+func redactContent(base, key string, value []byte, extra, paths []string) string {
+	if leaf, ok := strings.CutPrefix(key, explodedPrefix(base)); ok {
+		leaf = strings.ReplaceAll(leaf, "/", ".")
+		if matchSecretKey(leaf, extra) || matchSecretPath(leaf, paths) {
+			return RedactedValue
+		}
+		return string(value)
+	}
+
+	settings := make(map[string]interface{})
+	if err := yaml.Unmarshal(value, &settings); err != nil {
+		return fmt.Sprintf("<%d bytes>", len(value))
+	}
+	out, err := json.Marshal(redactSettings(settings, "", extra, paths))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes>", len(value))
+	}
+	return string(out)
+}
+
+// formatRedacted 实现配置结构体的 fmt.Formatter，隐藏敏感字段
+// 带有 secret:"true" 标签或键名匹配敏感模式的非空字段输出为 RedactedValue
+func formatRedacted(f fmt.State, verb rune, value interface{}) {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			fmt.Fprint(f, "<nil>")
+			return
+		}
+		rv = rv.Elem()
+	}
+	rt := rv.Type()
+
+	goSyntax := verb == 'v' && f.Flag('#')
+	withNames := goSyntax || f.Flag('+')
+	sep := " "
+	if goSyntax {
+		fmt.Fprint(f, rt.String())
+		sep = ", "
+	}
+
+	fmt.Fprint(f, "{")
+	written := 0
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if written > 0 {
+			fmt.Fprint(f, sep)
+		}
+		written++
+		if withNames {
+			fmt.Fprintf(f, "%s:", field.Name)
+		}
+
+		fv := rv.Field(i)
+		name, _ := parseTag(field)
+		if isSecretField(field, name) && !fv.IsZero() {
+			if goSyntax {
+				fmt.Fprintf(f, "%q", RedactedValue)
+			} else {
+				fmt.Fprint(f, RedactedValue)
+			}
+			continue
+		}
+		fmt.Fprintf(f, formatVerb(f, verb), fv.Interface())
+	}
+	fmt.Fprint(f, "}")
+}
+
+// isSecretField 判断结构体字段是否为敏感字段
+func isSecretField(field reflect.StructField, name string) bool {
+	if secret, ok := field.Tag.Lookup("secret"); ok {
+		return secret == "true"
+	}
+	return IsSecretKey(name)
+}
+
+// formatVerb 还原格式化动词及其标志，用于格式化子字段
+func formatVerb(f fmt.State, verb rune) string {
+	var b strings.Builder
+	b.WriteByte('%')
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			b.WriteRune(flag)
+		}
+	}
+	b.WriteRune(verb)
+	return b.String()
+}
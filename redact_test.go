@@ -0,0 +1,224 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatchSecretKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		extra []string
+		want  bool
+	}{
+		{"password", nil, true},
+		{"database.password", nil, true},
+		{"aliyun.access_key_secret", nil, true},
+		{"Aliyun.ACCESS-KEY-SECRET", nil, true},
+		{"openai.api_key", nil, true},
+		{"auth.refresh_token", nil, true},
+		{"auth.tokens", nil, false},
+		{"web_hook.key", nil, false},
+		{"password.length", nil, false},
+		{"database.dsn", nil, false},
+		{"database.dsn", []string{"dsn"}, true},
+		{"database.DSN", []string{"*dsn"}, true},
+	}
+	for _, tt := range tests {
+		if got := matchSecretKey(tt.key, tt.extra); got != tt.want {
+			t.Errorf("matchSecretKey(%q, %v) = %v, want %v", tt.key, tt.extra, got, tt.want)
+		}
+	}
+}
+
+type testSecretItem struct {
+	Name  string `mapstructure:"name"`
+	Token string `mapstructure:"credential" secret:"true"`
+}
+
+type testSecretConfig struct {
+	testSecretEmbedded `mapstructure:",squash"`
+	Hook               WorkwxWebHookConfig        `mapstructure:"web_hook"`
+	Items              []testSecretItem           `mapstructure:"items"`
+	Tenants            map[string]*testSecretItem `mapstructure:"tenants"`
+	Keys               map[string]string          `mapstructure:"keys" secret:"true"`
+	Next               *testSecretConfig          `mapstructure:"next"`
+}
+
+type testSecretEmbedded struct {
+	License string `mapstructure:"license" secret:"true"`
+}
+
+func TestSecretFieldPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  reflect.Type
+		want []string
+	}{
+		{
+			name: "nested structs",
+			typ:  reflect.TypeOf(WeixinConfig{}),
+			want: []string{"web_hook.key", "app.corp_secret", "app.token", "app.encoding_aes_key"},
+		},
+		{
+			name: "squash, slices, maps and cycles",
+			typ:  reflect.TypeOf(&testSecretConfig{}),
+			want: []string{"license", "web_hook.key", "items.credential", "tenants.*.credential", "keys"},
+		},
+		{
+			name: "no secret fields",
+			typ:  reflect.TypeOf(testPool{}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := secretFieldPaths(tt.typ, "", make(map[reflect.Type]bool))
+			if !sameStrings(got, tt.want) {
+				t.Fatalf("secretFieldPaths = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchSecretPath(t *testing.T) {
+	paths := []string{"web_hook.key", "tenants.*.credential", "keys"}
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"web_hook.key", true},
+		{"Web_Hook.KEY", true},
+		{"web_hook.subject", false},
+		{"key", false},
+		{"tenants.a.credential", true},
+		{"tenants.a.name", false},
+		{"keys.primary", true},
+		{"keysx", false},
+	}
+	for _, tt := range tests {
+		if got := matchSecretPath(tt.key, paths); got != tt.want {
+			t.Errorf("matchSecretPath(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRedactContent(t *testing.T) {
+	const base = "/configs/app/prod/weixin"
+	paths := secretFieldPaths(reflect.TypeOf(WeixinConfig{}), "", make(map[reflect.Type]bool))
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		extra []string
+		paths []string
+		want  string
+	}{
+		{
+			name:  "tagged field in single key content",
+			key:   base + "/content.yaml",
+			value: "corp_id: c1\nweb_hook:\n  key: SUPERSECRET\n  subject: s\n",
+			paths: paths,
+			want:  `{"corp_id":"c1","web_hook":{"key":"******","subject":"s"}}`,
+		},
+		{
+			name:  "tagged field unbound",
+			key:   base + "/content.yaml",
+			value: "web_hook:\n  key: SUPERSECRET\n",
+			want:  `{"web_hook":{"key":"SUPERSECRET"}}`,
+		},
+		{
+			name:  "key name pattern",
+			key:   base + "/content.json",
+			value: `{"db": {"password": "p", "hosts": ["a", "b"]}, "items": [{"token": "t"}]}`,
+			want:  `{"db":{"hosts":["a","b"],"password":"******"},"items":[{"token":"******"}]}`,
+		},
+		{
+			name:  "extra pattern",
+			key:   base + "/content.yaml",
+			value: "dsn: user:pw@tcp(a)/db\n",
+			extra: []string{"dsn"},
+			want:  `{"dsn":"******"}`,
+		},
+		{
+			name:  "exploded tagged leaf",
+			key:   base + "/content/web_hook/key",
+			value: "SUPERSECRET",
+			paths: paths,
+			want:  RedactedValue,
+		},
+		{
+			name:  "exploded pattern leaf",
+			key:   base + "/content/app/corp_secret",
+			value: "x",
+			want:  RedactedValue,
+		},
+		{
+			name:  "exploded plain leaf",
+			key:   base + "/content/web_hook/subject",
+			value: "s",
+			paths: paths,
+			want:  "s",
+		},
+		{
+			name:  "unparsable content",
+			key:   base + "/content.yaml",
+			value: "key: [SUPERSECRET\n",
+			want:  "<18 bytes>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactContent(base, tt.key, []byte(tt.value), tt.extra, tt.paths); got != tt.want {
+				t.Fatalf("redactContent = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatRedacted(t *testing.T) {
+	hook := WorkwxWebHookConfig{Key: "SUPERSECRET", Subscribe: "s"}
+	app := &WorkwxAppConfig{Address: "a", CorpSecret: "cs", AgentID: 1}
+
+	tests := []struct {
+		name   string
+		format string
+		value  interface{}
+		want   string
+	}{
+		{"plain", "%v", hook, "{****** s}"},
+		{"field names", "%+v", hook, "{Key:****** Subscribe:s}"},
+		{"go syntax", "%#v", hook, `config.WorkwxWebHookConfig{Key:"******", Subscribe:"s"}`},
+		{"empty secret shown as empty", "%+v", WorkwxWebHookConfig{Subscribe: "s"}, "{Key: Subscribe:s}"},
+		{"pointer", "%+v", app, "{Address:a CorpSecret:****** AgentID:1 Token: EncodingAESKey: TxSubscribe: RxSubscribe:}"},
+		{"string verb", "%s", hook, "{****** s}"},
+		{"nil pointer", "%v", (*WorkwxAppConfig)(nil), "<nil>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fmt.Sprintf(tt.format, tt.value)
+			if got != tt.want {
+				t.Fatalf("Sprintf(%q) = %s, want %s", tt.format, got, tt.want)
+			}
+			if strings.Contains(got, "SUPERSECRET") {
+				t.Fatalf("secret leaked: %s", got)
+			}
+		})
+	}
+}
+
+func TestGetConfigBindsSecretPaths(t *testing.T) {
+	m, _ := newFileManager(t, nil, map[string]string{
+		"app/prod/weixin.yaml": "web_hook:\n  key: k\napp:\n  address: a\n  corp_secret: c\n  agent_id: 1\n  token: t\n  encoding_aes_key: e\n",
+	})
+	if _, err := GetConfig[WeixinConfig](m, "app", "prod"); err != nil {
+		t.Fatal(err)
+	}
+	g := m.GetGroup("app", "prod", groupNameOf[WeixinConfig]()).(*configGroup)
+	want := []string{"web_hook.key", "app.corp_secret", "app.token", "app.encoding_aes_key"}
+	if got := g.boundSecretPaths(); !sameStrings(got, want) {
+		t.Fatalf("bound secret paths = %v, want %v", got, want)
+	}
+}
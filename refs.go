@@ -0,0 +1,291 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// refPattern 匹配配置值中的引用与变量
+//
+//	${file:/run/secrets/db_pw}  读取文件内容（去除末尾换行），文件变更后自动重新解析
+//	${env:DB_PW}                读取环境变量
+//
+// 开启 AppConfig.Interpolation 后还会解析以下变量，未开启时保持原样：
+//
+//	${app} / ${env}             配置组所属的应用与环境
+//	${key} / ${group.key}       本配置组或同一应用与环境下其他配置组的键，被引用的配置组变更后自动重新解析
+//	${key:-default}             变量未定义时使用默认值
+//	$${...}                     转义，输出字面量 ${...}
+var refPattern = regexp.MustCompile(`\$?\$\{([^}]*)\}`)
+
+// refSet 配置组解析时引用的外部资源
+type refSet struct {
+	// files 引用的文件
+	files []string
+	// groups 引用的其他配置组
+	groups []string
+}
+
+// materialize 应用环境变量覆盖，解密加密值并解析引用与变量，返回应用使用的配置与引用的外部资源
+// 没有任何值被替换时返回原配置
+func (m *ConfigManager) materialize(g *configGroup, v *viper.Viper) (*viper.Viper, refSet, error) {
+	settings := v.AllSettings()
+	overlaid := m.overlay(settings, g.name, g.boundEnvKeys())
+	r := newResolver(m, g, flattenSettings(settings))
+
+	changed, err := rewriteStrings(settings, "", func(path, value string) (string, error) {
+		out, err := r.resolve(g.name, path, value, nil)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+		return out, nil
+	})
+	if err != nil {
+		return nil, refSet{}, err
+	}
+
+	refs := r.refs()
+	if !changed && !overlaid {
+		return v, refs, nil
+	}
+
+	plain := viper.New()
+	if err := plain.MergeConfigMap(settings); err != nil {
+		return nil, refSet{}, err
+	}
+	return plain, refs, nil
+}
+
+// rewriteStrings 对配置树中的所有字符串叶子（含列表元素）调用 fn 并原地替换，返回是否有值被修改
+func rewriteStrings(settings map[string]interface{}, prefix string, fn func(path, value string) (string, error)) (bool, error) {
+	changed := false
+	for k, v := range settings {
+		path := joinKey(prefix, k)
+		switch val := v.(type) {
+		case map[string]interface{}:
+			c, err := rewriteStrings(val, path, fn)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		case []interface{}:
+			for i, item := range val {
+				s, ok := item.(string)
+				if !ok {
+					continue
+				}
+				out, err := fn(fmt.Sprintf("%s[%d]", path, i), s)
+				if err != nil {
+					return false, err
+				}
+				if out != s {
+					val[i] = out
+					changed = true
+				}
+			}
+		case string:
+			out, err := fn(path, val)
+			if err != nil {
+				return false, err
+			}
+			if out != val {
+				settings[k] = out
+				changed = true
+			}
+		}
+	}
+	return changed, nil
+}
+
+// resolveSource 读取文件或环境变量引用，引用的文件记录到 files
+func resolveSource(kind, target string, files map[string]bool) (string, error) {
+	switch kind {
+	case "file":
+		path, err := filepath.Abs(target)
+		if err != nil {
+			return "", err
+		}
+		files[path] = true
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	case "env":
+		value, ok := os.LookupEnv(target)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", target)
+		}
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown reference type %q", kind)
+	}
+}
+
+// refWatcher 监听配置组引用的文件，文件变更后重新解析引用所在的配置组
+// 监听文件所在目录，以兼容 Kubernetes 通过替换符号链接更新 Secret 的方式
+type refWatcher struct {
+	m *ConfigManager
+
+	mu      sync.Mutex
+	watcher *fsnotify.Watcher
+	// dirs 已监听的目录
+	dirs map[string]bool
+	// groups 按引用文件路径索引的配置组
+	groups map[string]map[*configGroup]bool
+}
+
+// trackRefs 更新配置组引用的文件，首次引用文件时启动监听
+func (m *ConfigManager) trackRefs(g *configGroup, files []string) {
+	m.refsOnce.Do(func() {
+		m.refs = &refWatcher{
+			m:      m,
+			dirs:   make(map[string]bool),
+			groups: make(map[string]map[*configGroup]bool),
+		}
+	})
+	m.refs.track(g, files)
+}
+
+// track 记录配置组引用的文件，并监听其所在目录，不再被引用的目录移除监听
+func (w *refWatcher) track(g *configGroup, files []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	defer w.prune()
+
+	for f, groups := range w.groups {
+		delete(groups, g)
+		if len(groups) == 0 {
+			delete(w.groups, f)
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	if w.watcher == nil {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			w.m.logger.Error("创建引用文件监听失败，引用文件变更将不会生效", zap.Error(err))
+			return
+		}
+		// 在管理器生命周期内运行，Stop 等待其结束并关闭监听
+		if !w.m.spawn(func() { w.run(watcher) }) {
+			watcher.Close()
+			return
+		}
+		w.watcher = watcher
+	}
+
+	for _, f := range files {
+		if w.groups[f] == nil {
+			w.groups[f] = make(map[*configGroup]bool)
+		}
+		w.groups[f][g] = true
+
+		dir := filepath.Dir(f)
+		if w.dirs[dir] {
+			continue
+		}
+		if err := w.watcher.Add(dir); err != nil {
+			w.m.logger.Warn("添加引用文件监听失败", zap.String("dir", dir), zap.Error(err))
+			continue
+		}
+		w.dirs[dir] = true
+	}
+}
+
+// prune 移除不再被任何配置组引用的目录监听，调用方需持有 mu
+func (w *refWatcher) prune() {
+	used := make(map[string]bool, len(w.groups))
+	for f := range w.groups {
+		used[filepath.Dir(f)] = true
+	}
+	for dir := range w.dirs {
+		if used[dir] {
+			continue
+		}
+		if err := w.watcher.Remove(dir); err != nil {
+			w.m.logger.Debug("移除引用文件监听失败", zap.String("dir", dir), zap.Error(err))
+		}
+		delete(w.dirs, dir)
+	}
+}
+
+// run 处理文件事件，防抖后重新解析受影响的配置组，直到管理器停止
+func (w *refWatcher) run(watcher *fsnotify.Watcher) {
+	defer watcher.Close()
+
+	timer := time.NewTimer(fileDebounce)
+	timer.Stop()
+	defer timer.Stop()
+	changedDirs := make(map[string]bool)
+
+	for {
+		select {
+		case <-w.m.ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			changedDirs[filepath.Dir(event.Name)] = true
+			timer.Reset(fileDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			w.m.logger.Warn("引用文件监听错误", zap.Error(err))
+		case <-timer.C:
+			for _, g := range w.affected(changedDirs) {
+				g.logger.Info("引用文件已变更，重新解析配置")
+				g.spawn(func() { w.m.reapply(g) })
+			}
+			changedDirs = make(map[string]bool)
+		}
+	}
+}
+
+// affected 返回引用了指定目录下文件的配置组
+func (w *refWatcher) affected(dirs map[string]bool) []*configGroup {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	seen := make(map[*configGroup]bool)
+	var out []*configGroup
+	for f, groups := range w.groups {
+		if !dirs[filepath.Dir(f)] {
+			continue
+		}
+		for g := range groups {
+			if !seen[g] {
+				seen[g] = true
+				out = append(out, g)
+			}
+		}
+	}
+	return out
+}
+
+// reapply 使用配置组当前的原始配置重新解析并应用，版本号与同步状态保持不变
+// 从读取原始配置到替换期间持有 applyMu，避免并发的监听更新被旧的原始配置覆盖
+func (m *ConfigManager) reapply(g *configGroup) {
+	g.applyMu.Lock()
+	defer g.applyMu.Unlock()
+
+	g.mu.RLock()
+	raw, revision := g.raw, g.revision
+	g.mu.RUnlock()
+	if raw != nil {
+		m.apply(g, raw, revision, false)
+	}
+}
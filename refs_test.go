@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestFileRefReresolution(t *testing.T) {
+	secrets := t.TempDir()
+	pw := filepath.Join(secrets, "db_pw")
+	writeTestFile(t, pw, "old\n")
+
+	m, _ := newFileManager(t, nil, map[string]string{
+		"app/prod/database.yaml": "host: a\npassword: ${file:" + pw + "}\n",
+	})
+	g := m.GetGroup("app", "prod", "database")
+	if got := g.GetString("password"); got != "old" {
+		t.Fatalf("password = %q, want old", got)
+	}
+
+	var mu sync.Mutex
+	var rejected []error
+	g.OnError(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		rejected = append(rejected, err)
+	})
+
+	// 引用文件变更后重新解析，配置组其余内容不变
+	rewriteUntil(t, pw, "new\n", func() bool { return g.GetString("password") == "new" }, "file reference to re-resolve")
+	if got := g.GetString("host"); got != "a" {
+		t.Fatalf("host = %q, want a", got)
+	}
+
+	// 以重命名方式替换引用文件（如 Kubernetes Secret 更新）同样生效
+	repeatUntil(t, func() {
+		tmp := filepath.Join(secrets, ".db_pw.tmp")
+		writeTestFile(t, tmp, "rotated")
+		if err := os.Rename(tmp, pw); err != nil {
+			t.Fatal(err)
+		}
+	}, func() bool { return g.GetString("password") == "rotated" }, "renamed file reference to re-resolve")
+
+	// 引用文件被删除时保留上一个有效值并上报错误
+	if err := os.Remove(pw); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(rejected) > 0
+	}, "missing reference to be rejected")
+	if got := g.GetString("password"); got != "rotated" {
+		t.Fatalf("password after file removed = %q, want rotated", got)
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	// retryMinBackoff 重新同步与重连的初始重试间隔
+	retryMinBackoff = time.Second
+	// retryMaxBackoff 重新同步与重连的最大重试间隔
+	retryMaxBackoff = 30 * time.Second
+)
+
+// snapshotPath 返回配置组快照文件路径，未启用缓存时返回空字符串
+func (m *ConfigManager) snapshotPath(key string) string {
+	if m.cfg.CacheDir == "" {
+		return ""
+	}
+	return filepath.Join(m.cfg.CacheDir, filepath.FromSlash(strings.TrimPrefix(key, "/")))
+}
+
+// saveSnapshot 将配置组当前生效的内容写入本地快照
+func (m *ConfigManager) saveSnapshot(key string, v *viper.Viper) {
+	path := m.snapshotPath(key)
+	if path == "" {
+		return
+	}
+
+	content, err := yaml.Marshal(v.AllSettings())
+	if err != nil {
+		m.logger.Warn("序列化配置快照失败", zap.String("key", key), zap.Error(err))
+		return
+	}
+	if err := writeFileAtomic(path, content); err != nil {
+		m.logger.Warn("写入配置快照失败", zap.String("path", path), zap.Error(err))
+	}
+}
+
+// loadSnapshot 从本地快照加载配置组
+func (m *ConfigManager) loadSnapshot(key string) (*viper.Viper, error) {
+	path := m.snapshotPath(key)
+	if path == "" {
+		return nil, fmt.Errorf("config cache is disabled")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config snapshot: %w", err)
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(content)); err != nil {
+		return nil, fmt.Errorf("failed to parse config snapshot %s: %w", path, err)
+	}
+	return v, nil
+}
+
+// reconcile 在后台重试读取远程配置，成功后替换快照或空配置
+func (m *ConfigManager) reconcile(g *configGroup) {
+	backoff := retryMinBackoff
+	for {
+		if !sleepContext(g.ctx, backoff) {
+			return
+		}
+
+		// 监听事件可能已先行完成同步
+		if !g.Stale() {
+			return
+		}
+
+		if m.resync(g, backoff) {
+			return
+		}
+		backoff = min(backoff*2, retryMaxBackoff)
+	}
+}
+
+// resync 重新读取所有配置层并应用，读取期间持有 applyMu，避免与监听事件交错
+func (m *ConfigManager) resync(g *configGroup, backoff time.Duration) bool {
+	g.applyMu.Lock()
+	defer g.applyMu.Unlock()
+
+	candidate, revision, err := m.loadLayers(g)
+	if err != nil {
+		g.logger.Debug("重新同步远程配置失败", zap.Duration("backoff", backoff), zap.Error(err))
+		return false
+	}
+
+	g.logger.Info("etcd 连接已恢复，与远程配置重新同步")
+	m.applyCandidate(g, candidate, revision)
+	return true
+}
+
+// sleepContext 等待 d 或 ctx 结束，ctx 结束时返回 false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// writeFileAtomic 先写临时文件再重命名，避免进程中断留下半截快照
+func writeFileAtomic(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
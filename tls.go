@@ -0,0 +1,124 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// tlsVersions 支持的 TLS 最低版本
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Validate 验证TLS配置
+func (cfg *TLSConfig) Validate() error {
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return fmt.Errorf("tls cert_file and key_file must be set together")
+	}
+	if cfg.MinVersion != "" {
+		if _, ok := tlsVersions[cfg.MinVersion]; !ok {
+			return fmt.Errorf("unsupported tls min_version: %s", cfg.MinVersion)
+		}
+	}
+	return nil
+}
+
+// ClientConfig 根据配置构建客户端 TLS 配置
+// 指定 CAFile 时仅信任该 CA，否则使用系统证书池；客户端证书在文件更新后自动重新加载
+func (cfg *TLSConfig) ClientConfig() (*tls.Config, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.MinVersion != "" {
+		tlsConfig.MinVersion = tlsVersions[cfg.MinVersion]
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in tls ca file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" {
+		reloader, err := newCertReloader(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = reloader.GetClientCertificate
+	}
+	return tlsConfig, nil
+}
+
+// certReloader 在握手时检查证书与私钥文件的修改时间，文件轮换后重新加载
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	certTime time.Time
+	keyTime  time.Time
+}
+
+// newCertReloader 加载客户端证书，加载失败时返回错误
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetClientCertificate 返回当前客户端证书，文件更新后重新加载
+// 重新加载失败时继续使用上一份证书，避免轮换过程中的半写文件导致连接中断
+func (r *certReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cert, err := r.load()
+	if err != nil && cert == nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// load 文件修改时间变化时重新加载证书，返回当前证书
+func (r *certReloader) load() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return r.cert, fmt.Errorf("failed to stat tls cert file: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return r.cert, fmt.Errorf("failed to stat tls key file: %w", err)
+	}
+	if r.cert != nil && certInfo.ModTime().Equal(r.certTime) && keyInfo.ModTime().Equal(r.keyTime) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return r.cert, fmt.Errorf("failed to load tls key pair: %w", err)
+	}
+	r.cert = &cert
+	r.certTime = certInfo.ModTime()
+	r.keyTime = keyInfo.ModTime()
+	return r.cert, nil
+}
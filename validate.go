@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/viper"
+)
+
+// Validator 配置校验接口
+// 配置类型实现该接口后，远程配置变更在生效前会先经过校验
+type Validator interface {
+	Validate() error
+}
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// validatorFunc 针对候选配置的校验函数
+type validatorFunc func(v *viper.Viper) error
+
+// registerValidator 若目标类型实现了 Validator 或包含 required 标签，则将其注册到配置组
+func registerValidator[T any](group ConfigGroup) {
+	g, ok := group.(*configGroup)
+	if !ok {
+		return
+	}
+
+	typeOf := reflect.TypeOf((*T)(nil)).Elem()
+	if !typeOf.Implements(validatorType) && !reflect.PointerTo(typeOf).Implements(validatorType) &&
+		!hasFieldTags(typeOf, make(map[reflect.Type]bool)) {
+		return
+	}
+
+	g.addValidator(typeOf, func(v *viper.Viper) error {
+		var candidate T
+		if err := unmarshalViper(v, &candidate); err != nil {
+			return fmt.Errorf("failed to unmarshal %s: %w", typeOf, err)
+		}
+		if err := validateValue(&candidate); err != nil {
+			return fmt.Errorf("%s: %w", typeOf, err)
+		}
+		return nil
+	})
+}
+
+// validateValue 对实现了 Validator 的值执行校验
+func validateValue(obj interface{}) error {
+	if v, ok := obj.(Validator); ok {
+		return v.Validate()
+	}
+	elem := reflect.ValueOf(obj)
+	if elem.Kind() == reflect.Ptr && !elem.IsNil() {
+		elem = elem.Elem()
+		if elem.Kind() == reflect.Ptr && elem.IsNil() {
+			return nil
+		}
+		if v, ok := elem.Interface().(Validator); ok {
+			return v.Validate()
+		}
+	}
+	return nil
+}
@@ -12,13 +12,76 @@ import (
 
 const TimeFormat = "2006-01-02 15:04:05"
 
+// fileLevels 按级别拆分的日志文件，error 文件包含 error 及以上级别
+var fileLevels = []string{"debug", "info", "warn", "error"}
+
 // NewZapLogger 根据日志配置创建Zap日志记录器
-// 支持多种输出格式（JSON/控制台）和分级日志输出
-// 返回配置好的日志记录器实例
+// 支持多种输出格式（JSON/控制台），日志级别由 Level 决定
+// ToFile 为 true 时额外按级别写入滚动日志文件，Development 为 true 时使用开发模式
 func NewZapLogger(logCfg LogConfig) *zap.Logger {
-	var options []zap.Option
-	var encoder zapcore.Encoder
+	logger, _ := NewZapLoggerWithLevels(logCfg)
+	return logger
+}
+
+// NewZapLoggerWithLevels 创建日志记录器，并返回可在运行时调整其级别的 LogLevels
+func NewZapLoggerWithLevels(logCfg LogConfig) (*zap.Logger, *LogLevels) {
+	levels := newLogLevels(toLevel(logCfg.Level))
+	// 输出端按最低级别过滤，各记录器再按自身级别过滤
+	level := levels.floor
+
+	// 彩色级别仅用于控制台输出，文件与 JSON 输出不包含颜色控制符
+	var cores []zapcore.Core
+	if console := consoleWriter(logCfg.Console); console != nil {
+		consoleEncoder := newEncoder(logCfg, logCfg.Format != "json")
+		cores = append(cores, zapcore.NewCore(consoleEncoder, console, level))
+	}
+
+	if logCfg.ToFile && logCfg.FileMode == LogFileModeSingle {
+		fileName := logCfg.FileName
+		if fileName == "" {
+			fileName = "app"
+		}
+		cores = append(cores, zapcore.NewCore(newEncoder(logCfg, false), toWriter(logCfg, fileName), level))
+	} else if logCfg.ToFile {
+		fileEncoder := newEncoder(logCfg, false)
+		for _, name := range fileLevels {
+			fileLevel := toLevel(name)
+			enabler := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+				if !level.Enabled(lvl) {
+					return false
+				}
+				if fileLevel == zapcore.ErrorLevel {
+					return lvl >= zapcore.ErrorLevel
+				}
+				return lvl == fileLevel
+			})
+			cores = append(cores, zapcore.NewCore(fileEncoder, toWriter(logCfg, name), enabler))
+		}
+	}
+
+	stackLevel := zap.ErrorLevel
+	options := []zap.Option{zap.AddCallerSkip(1)}
+	if logCfg.Development {
+		// 开发模式下 DPanic 会触发 panic，警告级别即输出调用栈
+		stackLevel = zap.WarnLevel
+		options = append(options, zap.Development())
+	}
+	options = append(options, zap.AddStacktrace(stackLevel))
+
+	tee := zapcore.NewTee(cores...)
+	if s := logCfg.Sampling; s != nil {
+		tick := s.Tick
+		if tick <= 0 {
+			tick = time.Second
+		}
+		tee = zapcore.NewSamplerWithOptions(tee, tick, s.Initial, s.Thereafter)
+	}
+	core := &levelCore{Core: tee, levels: levels}
+	return zap.New(core, options...), levels
+}
 
+// newEncoder 按配置创建编码器，color 仅对控制台格式生效
+func newEncoder(logCfg LogConfig, color bool) zapcore.Encoder {
 	encoderConfig := zapcore.EncoderConfig{
 		TimeKey:        "ts",
 		LevelKey:       "level",
@@ -28,49 +91,23 @@ func NewZapLogger(logCfg LogConfig) *zap.Logger {
 		MessageKey:     "msg",
 		StacktraceKey:  "stacktrace",
 		LineEnding:     zapcore.DefaultLineEnding,
-		EncodeLevel:    zapcore.CapitalColorLevelEncoder,
+		EncodeLevel:    zapcore.CapitalLevelEncoder,
 		EncodeDuration: zapcore.SecondsDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 		EncodeTime:     localTimeEncoder,
 	}
+	if logCfg.Development {
+		encoderConfig.EncodeDuration = zapcore.StringDurationEncoder
+		encoderConfig.EncodeCaller = zapcore.FullCallerEncoder
+	}
 
 	if logCfg.Format == "json" {
-		encoder = zapcore.NewJSONEncoder(encoderConfig)
-	} else {
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+		return zapcore.NewJSONEncoder(encoderConfig)
 	}
-
-	debugLevel := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl == zapcore.DebugLevel
-	})
-	debugCore := zapcore.NewCore(encoder, toWriter(logCfg.Directory, "debug"), debugLevel)
-
-	infoLevel := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl == zapcore.InfoLevel
-	})
-	infoCore := zapcore.NewCore(encoder, toWriter(logCfg.Directory, "info"), infoLevel)
-
-	warnLevel := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl == zapcore.WarnLevel
-	})
-	warnCore := zapcore.NewCore(encoder, toWriter(logCfg.Directory, "warn"), warnLevel)
-
-	errorLevel := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl >= zapcore.ErrorLevel
-	})
-	errorCore := zapcore.NewCore(encoder, toWriter(logCfg.Directory, "error"), errorLevel)
-
-	stackLevel := zap.NewAtomicLevel()
-	stackLevel.SetLevel(zap.ErrorLevel)
-
-	options = append(options,
-		zap.AddCallerSkip(1),
-		zap.AddStacktrace(stackLevel),
-	)
-
-	logger := zap.New(zapcore.NewTee(debugCore, infoCore, warnCore, errorCore), options...)
-
-	return logger
+	if color {
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	return zapcore.NewConsoleEncoder(encoderConfig)
 }
 
 func localTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
@@ -98,34 +135,43 @@ func toLevel(level string) zapcore.Level {
 	}
 }
 
-func toWriter(dir string, level string) zapcore.WriteSyncer {
-	fp := ""
-	sp := string(filepath.Separator)
-	fp, _ = filepath.Abs(filepath.Dir(filepath.Join(".")))
-	if dir != "" {
-		fp += sp + dir + sp
-	}
-	switch level {
-	case "debug":
-		return zapcore.AddSync(&lumberjack.Logger{ // 文件切割
-			Filename:   filepath.Join(fp, level) + ".log",
-			MaxSize:    100,
-			MaxAge:     7,
-			MaxBackups: 14,
-			LocalTime:  true,
-			Compress:   true,
-		})
+// consoleWriter 返回控制台输出，none 时返回 nil
+func consoleWriter(console string) zapcore.WriteSyncer {
+	switch console {
+	case LogConsoleNone:
+		return nil
+	case LogConsoleStderr:
+		return zapcore.Lock(os.Stderr)
 	default:
-		return zapcore.NewMultiWriteSyncer(
-			zapcore.AddSync(os.Stdout),
-			zapcore.AddSync(&lumberjack.Logger{ // 文件切割
-				Filename:   filepath.Join(fp, level) + ".log",
-				MaxSize:    100,
-				MaxAge:     7,
-				MaxBackups: 14,
-				LocalTime:  true,
-				Compress:   true,
-			}),
-		)
+		return zapcore.Lock(os.Stdout)
+	}
+}
+
+// logDir 解析日志目录，相对路径按 RelativeTo 指定的基准解析
+func logDir(logCfg LogConfig) string {
+	if filepath.IsAbs(logCfg.Directory) {
+		return logCfg.Directory
+	}
+	base, _ := os.Getwd()
+	if logCfg.RelativeTo == LogDirRelativeExecutable {
+		if exe, err := os.Executable(); err == nil {
+			base = filepath.Dir(exe)
+		}
 	}
+	return filepath.Join(base, logCfg.Directory)
+}
+
+func toWriter(logCfg LogConfig, name string) zapcore.WriteSyncer {
+	rotation := defaultLogRotation
+	if logCfg.Rotation != nil {
+		rotation = *logCfg.Rotation
+	}
+	return zapcore.AddSync(&lumberjack.Logger{ // 文件切割
+		Filename:   filepath.Join(logDir(logCfg), name) + ".log",
+		MaxSize:    rotation.MaxSize,
+		MaxAge:     rotation.MaxAge,
+		MaxBackups: rotation.MaxBackups,
+		LocalTime:  true,
+		Compress:   rotation.Compress,
+	})
 }